@@ -12,6 +12,9 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha1"
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/apheleia-project/apheleia/pkg/config"
 	"github.com/apheleia-project/apheleia/pkg/controller"
 	//+kubebuilder:scaffold:imports
 	"github.com/go-logr/logr"
@@ -26,10 +29,15 @@ func main() {
 	var enableLeaderElection bool
 	var probeAddr string
 	var abAPIExportName string
+	var enableWebhooks bool
+	var configFile string
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.StringVar(&abAPIExportName, "api-export-name", "jvm-build-service", "The name of the jvm-build-service APIExport.")
 
+	flag.StringVar(&configFile, "config", "", "The path of the ControllerConfig file, the defaults are used if it is not set.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", true, "Serve the admission webhooks, this requires serving certificates to be present.")
+
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -46,8 +54,13 @@ func main() {
 	ctx := ctrl.SetupSignalHandler()
 	restConfig := ctrl.GetConfigOrDie()
 
+	store, err := config.NewStore(configFile)
+	if err != nil {
+		mainLog.Error(err, "unable to load the controller configuration", "path", configFile)
+		os.Exit(1)
+	}
+
 	var mgr ctrl.Manager
-	var err error
 	mopts := ctrl.Options{
 		MetricsBindAddress:     metricsAddr,
 		Port:                   9443,
@@ -57,12 +70,26 @@ func main() {
 	}
 
 	mainLog.Info("The apis.kcp.dev group is not present - creating standard manager")
-	mgr, err = controller.NewManager(restConfig, mopts)
+	mgr, err = controller.NewManager(restConfig, mopts, store)
 	if err != nil {
 		mainLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
+	if enableWebhooks {
+		if err = (&v1alpha1.ComponentBuild{}).SetupWebhookWithManager(mgr); err != nil {
+			mainLog.Error(err, "unable to create webhook", "webhook", "ComponentBuild", "version", "v1alpha1")
+			os.Exit(1)
+		}
+		if err = (&v1alpha2.ComponentBuild{}).SetupWebhookWithManager(mgr); err != nil {
+			mainLog.Error(err, "unable to create webhook", "webhook", "ComponentBuild", "version", "v1alpha2")
+			os.Exit(1)
+		}
+		if err = (&v1alpha2.DeploymentTarget{}).SetupWebhookWithManager(mgr); err != nil {
+			mainLog.Error(err, "unable to create webhook", "webhook", "DeploymentTarget", "version", "v1alpha2")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
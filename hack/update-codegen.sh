@@ -20,5 +20,5 @@ echo ""
 GOFLAGS="" GOPATH=${GOPATH} /bin/bash ${CODEGEN_PKG}/generate-groups.sh "deepcopy,client,informer,lister" \
   github.com/apheleia-project/apheleia/pkg/client \
   github.com/apheleia-project/apheleia/pkg/apis \
-  "apheleia:v1alpha1" \
+  "apheleia:v1alpha1,v1alpha2" \
   --go-header-file "${SCRIPT_ROOT}/hack/boilerplate.go.txt"
@@ -67,12 +67,18 @@ public class DeployCommand implements Runnable {
     @CommandLine.Option(names = "--repo")
     String repo;
 
-    @CommandLine.Option(names = "--artifact", defaultValue = "all")
+    @CommandLine.Option(names = "--artifact", defaultValue = "all", description = "The rebuilt artifact to deploy, a comma separated list of rebuilt artifacts, or all")
     String artifact;
 
     @CommandLine.Option(names = "--force", defaultValue = "false")
     String force;
 
+    @CommandLine.Option(names = "--target", defaultValue = "", description = "The DeploymentTarget the artifacts are deployed to")
+    String target;
+
+    @CommandLine.Option(names = "--deployed-result", description = "The file that the names of the deployed rebuilt artifacts are written to")
+    Path deployedResult;
+
     public void run() {
         try {
             JBSConfig config = client.resources(JBSConfig.class).withName("jvm-build-config").get();
@@ -91,6 +97,9 @@ public class DeployCommand implements Runnable {
             String repoName = repo.substring(repo.lastIndexOf("maven/") + 6, repo.length() - 1);
             Regions region = Regions.fromName(regionSub.substring(regionSub.lastIndexOf('.') + 1));
             Log.infof("Deploying to %s, using region %s and repository %s", repo, region, repoName);
+            if (!target.isEmpty()) {
+                Log.infof("Deploying to target %s", target);
+            }
 
             var awsClient = AWSCodeArtifactClientBuilder.standard()
                     .withRegion(region)
@@ -110,15 +119,22 @@ public class DeployCommand implements Runnable {
                 rebuildArtifacts = client
                         .resources(RebuiltArtifact.class).list().getItems();
             } else {
+                //a comma separated list is used by batched deploys
                 rebuildArtifacts = new ArrayList<>();
-                RebuiltArtifact rebuildArtifact = client
-                        .resources(RebuiltArtifact.class).withName(this.artifact).get();
-                if (rebuildArtifact != null) {
-                    rebuildArtifacts.add(rebuildArtifact);
+                for (var name : this.artifact.split(",")) {
+                    RebuiltArtifact rebuildArtifact = client
+                            .resources(RebuiltArtifact.class).withName(name.trim()).get();
+                    if (rebuildArtifact != null) {
+                        rebuildArtifacts.add(rebuildArtifact);
+                    } else {
+                        Log.errorf("Unable to find rebuilt artifact %s", name);
+                    }
                 }
-
             }
 
+            //the result is written after each image, so it lists what was deployed even if a later image fails
+            List<String> deployed = new ArrayList<>();
+            writeDeployed(deployed);
             Map<String, List<RebuiltArtifact>> rebuiltArtifactMap = new HashMap<>();
             for (var i : rebuildArtifacts) {
                 if (i.getSpec().getImage() != null) {
@@ -232,7 +248,9 @@ public class DeployCommand implements Runnable {
                                             return rebuiltArtifact;
                                         }
                                     });
+                            deployed.add(i.getMetadata().getName());
                         }
+                        writeDeployed(deployed);
                     } else {
                         System.err.println("Failed to download " + e.getKey());
                     }
@@ -247,6 +265,12 @@ public class DeployCommand implements Runnable {
         }
     }
 
+    private void writeDeployed(List<String> deployed) throws IOException {
+        if (deployedResult != null) {
+            Files.writeString(deployedResult, String.join(",", deployed));
+        }
+    }
+
     private void handleThrottling(Runnable task) {
         for (int i = 0; i < 10; ++i) {
             try {
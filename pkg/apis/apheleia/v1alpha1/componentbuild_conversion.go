@@ -0,0 +1,203 @@
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// ArtifactDetailsAnnotation holds the v1alpha2 artifact fields that cannot be represented in v1alpha1, so that
+// they survive a v1alpha1 client reading and then updating the object
+const ArtifactDetailsAnnotation = "apheleia.io/v1alpha2-artifact-details"
+
+// RetriggerTargetAnnotation holds the name of the v1alpha2 retrigger target, which cannot be represented in v1alpha1
+const RetriggerTargetAnnotation = "apheleia.io/v1alpha2-retrigger-target"
+
+// StatusDetailsAnnotation holds the v1alpha2 status fields that cannot be represented in v1alpha1, so that a
+// v1alpha1 client that updates the status does not lose the deploy progress, the progress comment, the pending
+// events or the retrigger result
+const StatusDetailsAnnotation = "apheleia.io/v1alpha2-status-details"
+
+// statusDetails is the JSON that is stored in the StatusDetailsAnnotation
+type statusDetails struct {
+	Deploying int `json:"deploying,omitempty"`
+	Queued    int `json:"queued,omitempty"`
+	// ArtifactState holds the artifacts that have deploy details, the fields that v1alpha1 has are taken from it
+	ArtifactState   []v1alpha2.ArtifactState     `json:"artifactState,omitempty"`
+	CommitStatus    *v1alpha2.CommitStatus       `json:"commitStatus,omitempty"`
+	ProgressComment *v1alpha2.PullRequestComment `json:"progressComment,omitempty"`
+	PendingEvents   []v1alpha2.CloudEvent        `json:"pendingEvents,omitempty"`
+	EventSequence   int64                        `json:"eventSequence,omitempty"`
+	Retrigger       *v1alpha2.RetriggerStatus    `json:"retrigger,omitempty"`
+}
+
+var _ conversion.Convertible = &ComponentBuild{}
+
+// ConvertTo converts this ComponentBuild to the hub version (v1alpha2)
+func (src *ComponentBuild) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*v1alpha2.ComponentBuild)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	details := map[string]v1alpha2.ArtifactSpec{}
+	if raw, ok := dst.Annotations[ArtifactDetailsAnnotation]; ok {
+		var stored []v1alpha2.ArtifactSpec
+		if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+			return err
+		}
+		for _, i := range stored {
+			details[i.GAV()] = i
+		}
+		delete(dst.Annotations, ArtifactDetailsAnnotation)
+	}
+	dst.Spec.RetriggerTarget = dst.Annotations[RetriggerTargetAnnotation]
+	delete(dst.Annotations, RetriggerTargetAnnotation)
+	status := statusDetails{}
+	if raw, ok := dst.Annotations[StatusDetailsAnnotation]; ok {
+		if err := json.Unmarshal([]byte(raw), &status); err != nil {
+			return err
+		}
+		delete(dst.Annotations, StatusDetailsAnnotation)
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+
+	dst.Spec.SCMURL = src.Spec.SCMURL
+	dst.Spec.PRURL = src.Spec.PRURL
+	dst.Spec.Tag = src.Spec.Tag
+	dst.Spec.Artifacts = nil
+	for _, gav := range src.Spec.Artifacts {
+		if existing, ok := details[gav]; ok {
+			dst.Spec.Artifacts = append(dst.Spec.Artifacts, existing)
+		} else {
+			dst.Spec.Artifacts = append(dst.Spec.Artifacts, v1alpha2.ParseGAV(gav))
+		}
+	}
+
+	dst.Status.State = src.Status.State
+	dst.Status.Outstanding = src.Status.Outstanding
+	dst.Status.Message = src.Status.Message
+	dst.Status.ResultNotified = src.Status.ResultNotified
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.Deploying = status.Deploying
+	dst.Status.Queued = status.Queued
+	dst.Status.CommitStatus = status.CommitStatus
+	dst.Status.ProgressComment = status.ProgressComment
+	dst.Status.PendingEvents = status.PendingEvents
+	dst.Status.EventSequence = status.EventSequence
+	dst.Status.Retrigger = status.Retrigger
+	artifactDetails := map[string]v1alpha2.ArtifactState{}
+	for _, i := range status.ArtifactState {
+		artifactDetails[i.GAV] = i
+	}
+	dst.Status.ArtifactState = nil
+	gavs := make([]string, 0, len(src.Status.ArtifactState))
+	for gav := range src.Status.ArtifactState {
+		gavs = append(gavs, gav)
+	}
+	sort.Strings(gavs)
+	for _, gav := range gavs {
+		state := src.Status.ArtifactState[gav]
+		converted := artifactDetails[gav]
+		converted.GAV = gav
+		converted.ArtifactBuild = state.ArtifactBuild
+		converted.Built = state.Built
+		converted.Deployed = state.Deployed
+		converted.Failed = state.Failed
+		dst.Status.ArtifactState = append(dst.Status.ArtifactState, converted)
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v1alpha2) to this version
+func (dst *ComponentBuild) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*v1alpha2.ComponentBuild)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	dst.Spec.SCMURL = src.Spec.SCMURL
+	dst.Spec.PRURL = src.Spec.PRURL
+	dst.Spec.Tag = src.Spec.Tag
+	dst.Spec.Artifacts = nil
+	var details []v1alpha2.ArtifactSpec
+	for _, i := range src.Spec.Artifacts {
+		dst.Spec.Artifacts = append(dst.Spec.Artifacts, i.GAV())
+		if i.Classifier != "" || i.Type != "" || i.Scope != "" {
+			details = append(details, i)
+		}
+	}
+	if len(details) > 0 {
+		raw, err := json.Marshal(details)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[ArtifactDetailsAnnotation] = string(raw)
+	}
+	if src.Spec.RetriggerTarget != "" {
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[RetriggerTargetAnnotation] = src.Spec.RetriggerTarget
+	}
+
+	dst.Status.State = src.Status.State
+	dst.Status.Outstanding = src.Status.Outstanding
+	dst.Status.Message = src.Status.Message
+	dst.Status.ResultNotified = src.Status.ResultNotified
+	dst.Status.ObservedGeneration = src.Status.ObservedGeneration
+	dst.Status.Conditions = src.Status.Conditions
+	dst.Status.ArtifactState = nil
+	status := statusDetails{
+		Deploying:       src.Status.Deploying,
+		Queued:          src.Status.Queued,
+		CommitStatus:    src.Status.CommitStatus,
+		ProgressComment: src.Status.ProgressComment,
+		PendingEvents:   src.Status.PendingEvents,
+		EventSequence:   src.Status.EventSequence,
+		Retrigger:       src.Status.Retrigger,
+	}
+	if len(src.Status.ArtifactState) > 0 {
+		dst.Status.ArtifactState = map[string]ArtifactState{}
+		for _, i := range src.Status.ArtifactState {
+			dst.Status.ArtifactState[i.GAV] = ArtifactState{
+				ArtifactBuild: i.ArtifactBuild,
+				Built:         i.Built,
+				Deployed:      i.Deployed,
+				Failed:        i.Failed,
+			}
+			if i != (v1alpha2.ArtifactState{GAV: i.GAV, ArtifactBuild: i.ArtifactBuild, Built: i.Built, Deployed: i.Deployed, Failed: i.Failed}) {
+				status.ArtifactState = append(status.ArtifactState, i)
+			}
+		}
+	}
+	if !reflect.DeepEqual(status, statusDetails{}) {
+		raw, err := json.Marshal(status)
+		if err != nil {
+			return err
+		}
+		if dst.Annotations == nil {
+			dst.Annotations = map[string]string{}
+		}
+		dst.Annotations[StatusDetailsAnnotation] = string(raw)
+	}
+	//annotations are limited in size. The status details are left out if they do not fit, which resets them if a
+	//v1alpha1 client updates the object, but the artifact details are part of the spec and cannot be dropped.
+	if apivalidation.ValidateAnnotationsSize(dst.Annotations) != nil {
+		delete(dst.Annotations, StatusDetailsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+		if err := apivalidation.ValidateAnnotationsSize(dst.Annotations); err != nil {
+			return fmt.Errorf("ComponentBuild %s cannot be represented in v1alpha1: %w", src.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestConvertToHub(t *testing.T) {
+	g := NewGomegaWithT(t)
+	src := validComponentBuild()
+	src.Spec.Artifacts = append(src.Spec.Artifacts, "not-a-gav")
+	src.Status.State = ComponentBuildStateInProgress
+	src.Status.Outstanding = 1
+	src.Status.ArtifactState = map[string]ArtifactState{
+		"org.test:other-test_2.13:2.0.0.Final": {ArtifactBuild: "other", Built: true},
+		"com.test:test:1.0":                    {ArtifactBuild: "test", Built: true, Deployed: true},
+	}
+	dst := v1alpha2.ComponentBuild{}
+	g.Expect(src.ConvertTo(&dst)).To(Succeed())
+
+	g.Expect(dst.Name).To(Equal(src.Name))
+	g.Expect(dst.Spec.SCMURL).To(Equal(src.Spec.SCMURL))
+	g.Expect(dst.Spec.Artifacts).To(Equal([]v1alpha2.ArtifactSpec{
+		{Group: "com.test", Artifact: "test", Version: "1.0"},
+		{Group: "org.test", Artifact: "other-test_2.13", Version: "2.0.0.Final"},
+		{Artifact: "not-a-gav"},
+	}))
+	g.Expect(dst.Status.Outstanding).To(Equal(1))
+	//the list is sorted so the conversion is stable
+	g.Expect(dst.Status.ArtifactState).To(Equal([]v1alpha2.ArtifactState{
+		{GAV: "com.test:test:1.0", ArtifactBuild: "test", Built: true, Deployed: true},
+		{GAV: "org.test:other-test_2.13:2.0.0.Final", ArtifactBuild: "other", Built: true},
+	}))
+
+	back := ComponentBuild{}
+	g.Expect(back.ConvertFrom(&dst)).To(Succeed())
+	g.Expect(back.Spec).To(Equal(src.Spec))
+	g.Expect(back.Status).To(Equal(src.Status))
+	g.Expect(back.Annotations).To(BeEmpty())
+}
+
+func TestConvertFromHubPreservesArtifactDetails(t *testing.T) {
+	g := NewGomegaWithT(t)
+	src := v1alpha2.ComponentBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", Annotations: map[string]string{"keep": "me"}},
+		Spec: v1alpha2.ComponentBuildSpec{
+			Tag: "1.0",
+			Artifacts: []v1alpha2.ArtifactSpec{
+				{Group: "com.test", Artifact: "test", Version: "1.0", Classifier: "tests", Type: "jar", Scope: "test"},
+				{Group: "com.test", Artifact: "plain", Version: "1.0"},
+			},
+		},
+	}
+	v1 := ComponentBuild{}
+	g.Expect(v1.ConvertFrom(&src)).To(Succeed())
+	g.Expect(v1.Spec.Artifacts).To(Equal([]string{"com.test:test:1.0", "com.test:plain:1.0"}))
+	g.Expect(v1.Annotations).To(HaveKey(ArtifactDetailsAnnotation))
+	g.Expect(src.Annotations).NotTo(HaveKey(ArtifactDetailsAnnotation))
+
+	//a v1alpha1 client adds an artifact, the details of the existing ones must survive
+	v1.Spec.Artifacts = append(v1.Spec.Artifacts, "com.test:new:2.0")
+	dst := v1alpha2.ComponentBuild{}
+	g.Expect(v1.ConvertTo(&dst)).To(Succeed())
+	g.Expect(dst.Spec.Artifacts).To(Equal(append(src.Spec.Artifacts, v1alpha2.ArtifactSpec{Group: "com.test", Artifact: "new", Version: "2.0"})))
+	g.Expect(dst.Annotations).To(Equal(map[string]string{"keep": "me"}))
+}
+
+func TestConvertFromHubPreservesRetriggerTarget(t *testing.T) {
+	g := NewGomegaWithT(t)
+	src := v1alpha2.ComponentBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: v1alpha2.ComponentBuildSpec{
+			Tag:             "1.0",
+			RetriggerTarget: "jenkins",
+		},
+	}
+	v1 := ComponentBuild{}
+	g.Expect(v1.ConvertFrom(&src)).To(Succeed())
+	g.Expect(v1.Annotations).To(HaveKey(RetriggerTargetAnnotation))
+	dst := v1alpha2.ComponentBuild{}
+	g.Expect(v1.ConvertTo(&dst)).To(Succeed())
+	g.Expect(dst.Spec.RetriggerTarget).To(Equal(src.Spec.RetriggerTarget))
+	g.Expect(dst.Annotations).To(BeEmpty())
+}
+
+func TestConvertFromHubPreservesStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	now := metav1.Now()
+	src := v1alpha2.ComponentBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1alpha2.ComponentBuildSpec{Tag: "1.0", Artifacts: []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV("com.test:test:1.0"), v1alpha2.ParseGAV("com.test:plain:1.0")}},
+		Status: v1alpha2.ComponentBuildStatus{
+			State:     ComponentBuildStateInProgress,
+			Deploying: 1,
+			ArtifactState: []v1alpha2.ArtifactState{
+				{GAV: "com.test:plain:1.0", ArtifactBuild: "plain", Built: true, Deployed: true},
+				{GAV: "com.test:test:1.0", ArtifactBuild: "test", Built: true, Deploying: true, DeployAttempts: 2, LastDeployFailure: "timeout"},
+			},
+			CommitStatus:    &v1alpha2.CommitStatus{SHA: "abc", State: "pending"},
+			ProgressComment: &v1alpha2.PullRequestComment{ID: "42", Digest: "digest"},
+			PendingEvents:   []v1alpha2.CloudEvent{{ID: "test-1", Type: "io.apheleia.componentbuild.started", Time: now}},
+			EventSequence:   1,
+			Retrigger:       &v1alpha2.RetriggerStatus{Type: v1alpha2.RetriggerJenkins, Triggering: true},
+		},
+	}
+	v1 := ComponentBuild{}
+	g.Expect(v1.ConvertFrom(&src)).To(Succeed())
+	g.Expect(v1.Annotations).To(HaveKey(StatusDetailsAnnotation))
+
+	//a v1alpha1 client records that the artifact was deployed, the other status fields must survive
+	state := v1.Status.ArtifactState["com.test:test:1.0"]
+	state.Deployed = true
+	v1.Status.ArtifactState["com.test:test:1.0"] = state
+	dst := v1alpha2.ComponentBuild{}
+	g.Expect(v1.ConvertTo(&dst)).To(Succeed())
+	expected := src.Status.DeepCopy()
+	expected.ArtifactState[1].Deployed = true
+	g.Expect(dst.Status.ArtifactState).To(Equal(expected.ArtifactState))
+	g.Expect(dst.Status.Deploying).To(Equal(1))
+	g.Expect(dst.Status.CommitStatus).To(Equal(src.Status.CommitStatus))
+	g.Expect(dst.Status.ProgressComment).To(Equal(src.Status.ProgressComment))
+	g.Expect(dst.Status.PendingEvents).To(HaveLen(1))
+	g.Expect(dst.Status.PendingEvents[0].ID).To(Equal("test-1"))
+	g.Expect(dst.Status.EventSequence).To(Equal(int64(1)))
+	g.Expect(dst.Status.Retrigger).To(Equal(src.Status.Retrigger))
+	g.Expect(dst.Annotations).To(BeEmpty())
+}
+
+func TestConvertFromHubAnnotationSize(t *testing.T) {
+	g := NewGomegaWithT(t)
+	src := v1alpha2.ComponentBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec:       v1alpha2.ComponentBuildSpec{Tag: "1.0"},
+	}
+	for i := 0; i < 2000; i++ {
+		gav := fmt.Sprintf("com.test:artifact-%d:1.0", i)
+		src.Spec.Artifacts = append(src.Spec.Artifacts, v1alpha2.ParseGAV(gav))
+		src.Status.ArtifactState = append(src.Status.ArtifactState, v1alpha2.ArtifactState{GAV: gav, ArtifactBuild: fmt.Sprintf("artifact-%d", i), Built: true, DeployAttempts: 1, LastDeployFailure: strings.Repeat("x", 200)})
+	}
+
+	//the status details do not fit into the annotations, so they are left out
+	v1 := ComponentBuild{}
+	g.Expect(v1.ConvertFrom(&src)).To(Succeed())
+	g.Expect(v1.Annotations).NotTo(HaveKey(StatusDetailsAnnotation))
+	g.Expect(v1.Status.ArtifactState).To(HaveLen(2000))
+
+	//the artifact details are part of the spec, so the conversion fails if they do not fit
+	for i := range src.Spec.Artifacts {
+		src.Spec.Artifacts[i].Classifier = strings.Repeat("c", 200)
+	}
+	g.Expect(v1.ConvertFrom(&src)).NotTo(Succeed())
+}
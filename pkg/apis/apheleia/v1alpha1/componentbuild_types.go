@@ -10,6 +10,36 @@ const (
 	ComponentBuildStateInProgress = "ComponentBuildBuildInProgress"
 )
 
+const (
+	// ConditionArtifactsBuilt is true once every artifact in the spec has been rebuilt
+	ConditionArtifactsBuilt = "ArtifactsBuilt"
+	// ConditionArtifactsDeployed is true once every rebuilt artifact has been deployed
+	ConditionArtifactsDeployed = "ArtifactsDeployed"
+	// ConditionConfigValid is true when the namespace has a usable deployment config
+	ConditionConfigValid = "ConfigValid"
+	// ConditionNotified is true once the result has been reported back to the pull request
+	ConditionNotified = "Notified"
+	// ConditionReady is true once every artifact has been built and deployed
+	ConditionReady = "Ready"
+)
+
+const (
+	ReasonConfigMissing          = "ConfigMissing"
+	ReasonConfigFound            = "ConfigFound"
+	ReasonBuilding               = "Building"
+	ReasonBuildFailed            = "BuildFailed"
+	ReasonAllBuilt               = "AllBuilt"
+	ReasonDeploying              = "Deploying"
+	ReasonAllDeployed            = "AllDeployed"
+	ReasonNoPullRequest          = "NoPullRequest"
+	ReasonWaitingForCompletion   = "WaitingForCompletion"
+	ReasonNotificationInProgress = "NotificationInProgress"
+	ReasonNotified               = "Notified"
+	ReasonInProgress             = "InProgress"
+	ReasonFailed                 = "Failed"
+	ReasonComplete               = "Complete"
+)
+
 type ComponentBuildSpec struct {
 	SCMURL    string   `json:"scmURL,omitempty"`
 	PRURL     string   `json:"prURL,omitempty"`
@@ -23,6 +53,13 @@ type ComponentBuildStatus struct {
 	ArtifactState  map[string]ArtifactState `json:"artifactState,omitempty"`
 	Message        string                   `json:"message,omitempty"`
 	ResultNotified bool                     `json:"resultNotified,omitempty"`
+	// ObservedGeneration is the most recent generation of the spec the controller has acted on
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest available observations of the build
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //type ArtifactBuildState string
@@ -35,6 +72,7 @@ type ComponentBuildStatus struct {
 // +kubebuilder:printcolumn:name="Tag",type=string,JSONPath=`.spec.tag`
 // +kubebuilder:printcolumn:name="Outstanding",type=integer,JSONPath=`.status.outstanding`
 // +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
 // +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`
 // ComponentBuild A build of an upstream component
 type ComponentBuild struct {
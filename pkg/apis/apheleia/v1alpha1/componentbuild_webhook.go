@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	"fmt"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (r *ComponentBuild) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-apheleia-io-v1alpha1-componentbuild,mutating=false,failurePolicy=fail,sideEffects=None,groups=apheleia.io,resources=componentbuilds,verbs=create;update,versions=v1alpha1,name=vcomponentbuild.apheleia.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ComponentBuild{}
+
+// ValidateCreate implements webhook.Validator
+func (r *ComponentBuild) ValidateCreate() error {
+	return r.invalid(r.validateSpec(nil))
+}
+
+// ValidateUpdate implements webhook.Validator
+func (r *ComponentBuild) ValidateUpdate(old runtime.Object) error {
+	oldCb, ok := old.(*ComponentBuild)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ComponentBuild but got a %T", old))
+	}
+	if r.DeletionTimestamp != nil {
+		//never block finalizer removal
+		return nil
+	}
+	specPath := field.NewPath("spec")
+	allErrs := r.validateSpec(oldCb)
+	if r.Spec.SCMURL != oldCb.Spec.SCMURL {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("scmURL"), r.Spec.SCMURL, "field is immutable"))
+	}
+	if r.Spec.Tag != oldCb.Spec.Tag {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("tag"), r.Spec.Tag, "field is immutable"))
+	}
+	return r.invalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *ComponentBuild) ValidateDelete() error {
+	return nil
+}
+
+// validateSpec checks the spec, if old is not nil artifacts that were already present are not validated again
+// so that objects created before validation was introduced can still be updated
+func (r *ComponentBuild) validateSpec(old *ComponentBuild) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	existing := map[string]int{}
+	if old != nil {
+		for _, i := range old.Spec.Artifacts {
+			existing[i]++
+		}
+	}
+	seen := map[string]int{}
+	for i, gav := range r.Spec.Artifacts {
+		path := specPath.Child("artifacts").Index(i)
+		seen[gav]++
+		if seen[gav] > 1 {
+			if seen[gav] > existing[gav] {
+				allErrs = append(allErrs, field.Duplicate(path, gav))
+			}
+			continue
+		}
+		if existing[gav] > 0 {
+			continue
+		}
+		if err := v1alpha2.ValidateGAV(gav); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, gav, err.Error()))
+		}
+	}
+	if r.Spec.SCMURL != "" && (old == nil || old.Spec.SCMURL != r.Spec.SCMURL) {
+		if err := v1alpha2.ValidateSCMURL(r.Spec.SCMURL); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("scmURL"), r.Spec.SCMURL, err.Error()))
+		}
+	}
+	if r.Spec.PRURL != "" && (old == nil || old.Spec.PRURL != r.Spec.PRURL) {
+		if err := v1alpha2.ValidateHTTPURL(r.Spec.PRURL); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("prURL"), r.Spec.PRURL, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+func (r *ComponentBuild) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(Kind("ComponentBuild"), r.Name, allErrs)
+}
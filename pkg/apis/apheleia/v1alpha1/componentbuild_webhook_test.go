@@ -0,0 +1,99 @@
+package v1alpha1
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func validComponentBuild() *ComponentBuild {
+	return &ComponentBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: ComponentBuildSpec{
+			SCMURL:    "https://github.com/test/test.git",
+			PRURL:     "https://github.com/test/test/pull/1",
+			Tag:       "1.0",
+			Artifacts: []string{"com.test:test:1.0", "org.test:other-test_2.13:2.0.0.Final"},
+		},
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cb *ComponentBuild)
+		field  string
+	}{
+		{name: "valid"},
+		{name: "scp style scm url", modify: func(cb *ComponentBuild) { cb.Spec.SCMURL = "git@github.com:test/test.git" }},
+		{name: "missing version", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts = []string{"com.test:test"} }, field: "spec.artifacts[0]"},
+		{name: "too many parts", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts = []string{"com.test:test:jar:1.0"} }, field: "spec.artifacts[0]"},
+		{name: "bad group", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts = []string{"com/test:test:1.0"} }, field: "spec.artifacts[0]"},
+		{name: "bad version", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts = []string{"com.test:test:1 0"} }, field: "spec.artifacts[0]"},
+		{name: "duplicate", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts = append(cb.Spec.Artifacts, "com.test:test:1.0") }, field: "spec.artifacts[2]"},
+		{name: "relative scm url", modify: func(cb *ComponentBuild) { cb.Spec.SCMURL = "test/test.git" }, field: "spec.scmURL"},
+		{name: "pr url not http", modify: func(cb *ComponentBuild) { cb.Spec.PRURL = "ftp://github.com/test/test/pull/1" }, field: "spec.prURL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cb := validComponentBuild()
+			if tt.modify != nil {
+				tt.modify(cb)
+			}
+			err := cb.ValidateCreate()
+			if tt.field == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(err.Error()).To(ContainSubstring(tt.field))
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	g := NewGomegaWithT(t)
+	old := validComponentBuild()
+
+	//adding artifacts is what the analyser does on every run
+	updated := validComponentBuild()
+	updated.Spec.Artifacts = append(updated.Spec.Artifacts, "io.test:new:3.0")
+	g.Expect(updated.ValidateUpdate(old)).To(Succeed())
+
+	updated = validComponentBuild()
+	updated.Spec.SCMURL = "https://github.com/test/other.git"
+	err := updated.ValidateUpdate(old)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("spec.scmURL"))
+
+	updated = validComponentBuild()
+	updated.Spec.Tag = "2.0"
+	err = updated.ValidateUpdate(old)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("spec.tag"))
+
+	//artifacts that predate validation do not block updates
+	old.Spec.Artifacts = append(old.Spec.Artifacts, "not-a-gav")
+	updated = old.DeepCopy()
+	updated.Labels = map[string]string{"test": "true"}
+	g.Expect(updated.ValidateUpdate(old)).To(Succeed())
+}
+
+func TestValidateUpdateExistingDuplicates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	//duplicates that predate validation do not block updates, the same as in v1alpha2
+	old := validComponentBuild()
+	old.Spec.Artifacts = append(old.Spec.Artifacts, "com.test:test:1.0")
+	updated := old.DeepCopy()
+	updated.Labels = map[string]string{"test": "true"}
+	g.Expect(updated.ValidateUpdate(old)).To(Succeed())
+
+	//but another copy is rejected
+	updated.Spec.Artifacts = append(updated.Spec.Artifacts, "com.test:test:1.0")
+	err := updated.ValidateUpdate(old)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("spec.artifacts[3]"))
+}
@@ -0,0 +1,4 @@
+package v1alpha2
+
+// Hub marks this type as a conversion hub.
+func (*ComponentBuild) Hub() {}
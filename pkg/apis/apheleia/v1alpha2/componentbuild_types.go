@@ -0,0 +1,297 @@
+package v1alpha2
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ComponentBuildStateFailed     = "ComponentBuildFailed"
+	ComponentBuildStateComplete   = "ComponentBuildComplete"
+	ComponentBuildStateInProgress = "ComponentBuildBuildInProgress"
+)
+
+const (
+	// ConditionArtifactsBuilt is true once every artifact in the spec has been rebuilt
+	ConditionArtifactsBuilt = "ArtifactsBuilt"
+	// ConditionArtifactsDeployed is true once every rebuilt artifact has been deployed
+	ConditionArtifactsDeployed = "ArtifactsDeployed"
+	// ConditionConfigValid is true when the namespace has a usable deployment config
+	ConditionConfigValid = "ConfigValid"
+	// ConditionNotified is true once the result has been reported back to the pull request
+	ConditionNotified = "Notified"
+	// ConditionReady is true once every artifact has been built and deployed
+	ConditionReady = "Ready"
+	// ConditionTemplatesValid is false when a notification template in the namespace config is invalid
+	ConditionTemplatesValid = "NotificationTemplatesValid"
+)
+
+const (
+	ReasonConfigMissing          = "ConfigMissing"
+	ReasonConfigFound            = "ConfigFound"
+	ReasonTargetFound            = "DeploymentTargetFound"
+	ReasonTargetNotUsable        = "DeploymentTargetNotUsable"
+	ReasonAmbiguousTarget        = "AmbiguousDeploymentTarget"
+	ReasonBuilding               = "Building"
+	ReasonBuildFailed            = "BuildFailed"
+	ReasonAllBuilt               = "AllBuilt"
+	ReasonDeploying              = "Deploying"
+	ReasonDeployQueued           = "DeployQueued"
+	ReasonDeployFailed           = "DeployFailed"
+	ReasonAllDeployed            = "AllDeployed"
+	ReasonNoPullRequest          = "NoPullRequest"
+	ReasonWaitingForCompletion   = "WaitingForCompletion"
+	ReasonNotificationInProgress = "NotificationInProgress"
+	ReasonNotified               = "Notified"
+	ReasonTemplatesValid         = "TemplatesValid"
+	ReasonInvalidTemplate        = "InvalidTemplate"
+	ReasonInProgress             = "InProgress"
+	ReasonFailed                 = "Failed"
+	ReasonComplete               = "Complete"
+)
+
+const (
+	// RetriggerJenkins calls a Jenkins remote build trigger URL
+	RetriggerJenkins = "Jenkins"
+	// RetriggerWebhook posts the ComponentBuild as JSON to a URL
+	RetriggerWebhook = "Webhook"
+	// RetriggerTekton creates a PipelineRun from a template
+	RetriggerTekton = "Tekton"
+)
+
+const (
+	// RetriggerTokenKey is the key in the retrigger secret that holds the Jenkins build token or the webhook bearer
+	// token
+	RetriggerTokenKey = "token"
+	// RetriggerUsernameKey and RetriggerPasswordKey are the keys in the retrigger secret that hold the Jenkins user and
+	// API token the trigger is authenticated with
+	RetriggerUsernameKey = "username"
+	RetriggerPasswordKey = "password"
+)
+
+type ComponentBuildSpec struct {
+	SCMURL string `json:"scmURL,omitempty"`
+	PRURL  string `json:"prURL,omitempty"`
+	Tag    string `json:"tag,omitempty"`
+	// Artifacts are the community dependencies that need to be rebuilt
+	// +listType=map
+	// +listMapKey=group
+	// +listMapKey=artifact
+	// +listMapKey=version
+	Artifacts []ArtifactSpec `json:"artifacts,omitempty"`
+	// RetriggerTarget is the name of the entry in the retriggerTargets of the DeploymentTarget that is triggered once
+	// every artifact has been rebuilt and deployed, if it is not set the retrigger of the DeploymentTarget is used.
+	// Targets are only defined in the DeploymentTarget, as they use its credentials and create PipelineRuns.
+	// +optional
+	RetriggerTarget string `json:"retriggerTarget,omitempty"`
+}
+
+// RetriggerTarget describes how to trigger a new build of the component
+type RetriggerTarget struct {
+	// Type is how the build is triggered
+	// +kubebuilder:validation:Enum=Jenkins;Webhook;Tekton
+	Type string `json:"type"`
+	// URL is the Jenkins remote build trigger URL, e.g. https://jenkins.example.com/job/app/build, or the URL the
+	// webhook is posted to
+	URL string `json:"url,omitempty"`
+	// Secret is the name of the secret holding the credentials. Its token key is passed as the Jenkins build token or
+	// sent as the webhook bearer token, and Jenkins requests are authenticated with its username and password keys.
+	Secret string `json:"secret,omitempty"`
+	// PipelineRunTemplate is the PipelineRun that is created for Tekton, as YAML. It is a Go template that is
+	// rendered with the same data as the notification templates.
+	PipelineRunTemplate string `json:"pipelineRunTemplate,omitempty"`
+}
+
+// ArtifactSpec identifies a single maven artifact
+type ArtifactSpec struct {
+	Group    string `json:"group"`
+	Artifact string `json:"artifact"`
+	Version  string `json:"version"`
+	// Classifier is the maven classifier of the artifact that was found, e.g. sources
+	Classifier string `json:"classifier,omitempty"`
+	// Type is the maven type of the artifact that was found, e.g. jar or pom
+	Type string `json:"type,omitempty"`
+	// Scope is the maven scope the dependency was found in
+	Scope string `json:"scope,omitempty"`
+}
+
+// GAV returns the groupId:artifactId:version coordinate that is used to build the artifact
+func (a *ArtifactSpec) GAV() string {
+	if a.Group == "" && a.Version == "" {
+		//an entry that could not be parsed when converting from v1alpha1
+		return a.Artifact
+	}
+	return a.Group + ":" + a.Artifact + ":" + a.Version
+}
+
+// ParseGAV parses a groupId:artifactId:version coordinate. Strings that cannot be parsed are stored as the
+// artifact so that they are not lost.
+func ParseGAV(gav string) ArtifactSpec {
+	parts := strings.Split(gav, ":")
+	if len(parts) == 3 && parts[0] != "" && parts[2] != "" {
+		return ArtifactSpec{Group: parts[0], Artifact: parts[1], Version: parts[2]}
+	}
+	return ArtifactSpec{Artifact: gav}
+}
+
+type ComponentBuildStatus struct {
+	State       string `json:"state,omitempty"`
+	Outstanding int    `json:"outstanding,omitempty"`
+	// Deploying is the number of artifacts that are currently being deployed
+	Deploying int `json:"deploying,omitempty"`
+	// Queued is the number of built artifacts that are waiting for a free deploy slot in the namespace
+	Queued int `json:"queued,omitempty"`
+	// ArtifactState is the state of each artifact in the spec
+	// +listType=map
+	// +listMapKey=gav
+	ArtifactState  []ArtifactState `json:"artifactState,omitempty"`
+	Message        string          `json:"message,omitempty"`
+	ResultNotified bool            `json:"resultNotified,omitempty"`
+	// CommitStatus is the status that was last set on the commit the ComponentBuild was created for
+	// +optional
+	CommitStatus *CommitStatus `json:"commitStatus,omitempty"`
+	// ProgressComment is the comment on the pull request that shows the progress of the build
+	// +optional
+	ProgressComment *PullRequestComment `json:"progressComment,omitempty"`
+	// PendingEvents are the CloudEvents that have not been delivered to the sink of the namespace yet, they are
+	// retried until they are
+	// +optional
+	PendingEvents []CloudEvent `json:"pendingEvents,omitempty"`
+	// EventSequence is the number of CloudEvents that have been emitted for the ComponentBuild, it makes their ids
+	// unique
+	EventSequence int64 `json:"eventSequence,omitempty"`
+	// Retrigger records the CI build that was triggered once the ComponentBuild completed
+	// +optional
+	Retrigger *RetriggerStatus `json:"retrigger,omitempty"`
+	// ObservedGeneration is the most recent generation of the spec the controller has acted on
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest available observations of the build
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// CommitStatus is a status reported on a commit in GitHub or GitLab
+type CommitStatus struct {
+	// SHA is the commit that the tag resolved to
+	SHA string `json:"sha"`
+	// State is pending, success or failure
+	State       string `json:"state"`
+	Description string `json:"description,omitempty"`
+}
+
+// CloudEvent is a CloudEvent about the ComponentBuild that still has to be delivered
+type CloudEvent struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	// GAV is the artifact the event is about, it is empty for events about the ComponentBuild itself
+	GAV  string      `json:"gav,omitempty"`
+	Time metav1.Time `json:"time"`
+	// Attempts is the number of times delivering the event has failed
+	Attempts        int          `json:"attempts,omitempty"`
+	LastFailure     string       `json:"lastFailure,omitempty"`
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+}
+
+// RetriggerStatus is the result of triggering a new build of the component
+type RetriggerStatus struct {
+	Type string `json:"type"`
+	// Triggering is written before the build is triggered. If it is set without Triggered the result of that attempt
+	// was lost, and the build is not triggered again.
+	Triggering bool `json:"triggering,omitempty"`
+	// Triggered is true once the build has been triggered, it is only triggered once per completion
+	Triggered bool `json:"triggered,omitempty"`
+	// Target is the URL that was called, or the name of the PipelineRun that was created
+	Target        string       `json:"target,omitempty"`
+	TriggeredTime *metav1.Time `json:"triggeredTime,omitempty"`
+	// Attempts is the number of times triggering the build has failed
+	Attempts        int          `json:"attempts,omitempty"`
+	LastFailure     string       `json:"lastFailure,omitempty"`
+	LastAttemptTime *metav1.Time `json:"lastAttemptTime,omitempty"`
+}
+
+// PullRequestComment is a comment that is edited in place as the build progresses
+type PullRequestComment struct {
+	// ID identifies the comment to the GitHub or GitLab API
+	ID string `json:"id"`
+	// Digest is the sha256 of the body that was last written, the comment is only edited when it changes
+	Digest string `json:"digest,omitempty"`
+}
+
+// GetArtifactState returns the state for the given GAV, or nil if it is not present
+func (s *ComponentBuildStatus) GetArtifactState(gav string) *ArtifactState {
+	for i := range s.ArtifactState {
+		if s.ArtifactState[i].GAV == gav {
+			return &s.ArtifactState[i]
+		}
+	}
+	return nil
+}
+
+// SetArtifactState adds or replaces the state for the GAV of the given state
+func (s *ComponentBuildStatus) SetArtifactState(state ArtifactState) {
+	existing := s.GetArtifactState(state.GAV)
+	if existing != nil {
+		*existing = state
+		return
+	}
+	s.ArtifactState = append(s.ArtifactState, state)
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=componentbuilds,scope=Namespaced
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.scmURL`
+// +kubebuilder:printcolumn:name="Tag",type=string,JSONPath=`.spec.tag`
+// +kubebuilder:printcolumn:name="Outstanding",type=integer,JSONPath=`.status.outstanding`
+// +kubebuilder:printcolumn:name="Deploying",type=integer,JSONPath=`.status.deploying`,priority=1
+// +kubebuilder:printcolumn:name="Queued",type=integer,JSONPath=`.status.queued`,priority=1
+// +kubebuilder:printcolumn:name="State",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="Ready",type=string,JSONPath=`.status.conditions[?(@.type=="Ready")].status`
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.message`
+// ComponentBuild A build of an upstream component
+type ComponentBuild struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ComponentBuildSpec   `json:"spec"`
+	Status ComponentBuildStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ComponentBuildList contains a list of ComponentBuild
+type ComponentBuildList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ComponentBuild `json:"items"`
+}
+
+type ArtifactState struct {
+	GAV           string `json:"gav"`
+	ArtifactBuild string `json:"artifactBuild,omitempty"`
+	Built         bool   `json:"built,omitempty"`
+	Deployed      bool   `json:"deployed,omitempty"`
+	Failed        bool   `json:"failed,omitempty"`
+	// Deploying is true while the artifact is being deployed
+	Deploying bool `json:"deploying,omitempty"`
+	// Queued is true while the artifact is waiting for a free deploy slot
+	Queued bool `json:"queued,omitempty"`
+	// DeployAttempts is the number of times deploying the artifact has failed
+	DeployAttempts int `json:"deployAttempts,omitempty"`
+	// LastDeployFailure is the reason the last deploy attempt failed
+	LastDeployFailure string `json:"lastDeployFailure,omitempty"`
+	// DeployFailed is true once the artifact has failed to deploy too many times, it is not retried after that
+	DeployFailed bool `json:"deployFailed,omitempty"`
+}
+
+func (as *ArtifactState) Done() bool {
+	if as.Built && as.Deployed {
+		return true
+	}
+	return false
+}
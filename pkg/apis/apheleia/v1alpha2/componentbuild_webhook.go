@@ -0,0 +1,208 @@
+package v1alpha2
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+var (
+	mavenIdentifier = regexp.MustCompile(`^[A-Za-z0-9_\-.]+$`)
+	mavenVersion    = regexp.MustCompile(`^[^\s:/\\]+$`)
+	scpLikeURL      = regexp.MustCompile(`^[A-Za-z0-9_\-.]+@[A-Za-z0-9\-.]+:[^\s]+$`)
+	mavenScopes     = map[string]bool{"compile": true, "provided": true, "runtime": true, "test": true, "system": true, "import": true}
+)
+
+func (r *ComponentBuild) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-apheleia-io-v1alpha2-componentbuild,mutating=false,failurePolicy=fail,sideEffects=None,groups=apheleia.io,resources=componentbuilds,verbs=create;update,versions=v1alpha2,name=vcomponentbuild-v1alpha2.apheleia.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &ComponentBuild{}
+
+// ValidateCreate implements webhook.Validator
+func (r *ComponentBuild) ValidateCreate() error {
+	return r.invalid(r.validateSpec(nil))
+}
+
+// ValidateUpdate implements webhook.Validator
+func (r *ComponentBuild) ValidateUpdate(old runtime.Object) error {
+	oldCb, ok := old.(*ComponentBuild)
+	if !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a ComponentBuild but got a %T", old))
+	}
+	if r.DeletionTimestamp != nil {
+		//never block finalizer removal
+		return nil
+	}
+	specPath := field.NewPath("spec")
+	allErrs := r.validateSpec(oldCb)
+	if r.Spec.SCMURL != oldCb.Spec.SCMURL {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("scmURL"), r.Spec.SCMURL, "field is immutable"))
+	}
+	if r.Spec.Tag != oldCb.Spec.Tag {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("tag"), r.Spec.Tag, "field is immutable"))
+	}
+	return r.invalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *ComponentBuild) ValidateDelete() error {
+	return nil
+}
+
+// validateSpec checks the spec, if old is not nil artifacts that were already present are not validated again
+// so that objects created before validation was introduced can still be updated. This includes duplicates, as long
+// as there are no more of them than before, the same as in v1alpha1.
+func (r *ComponentBuild) validateSpec(old *ComponentBuild) field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+	existing := map[ArtifactSpec]bool{}
+	duplicates := map[string]int{}
+	if old != nil {
+		for _, i := range old.Spec.Artifacts {
+			existing[i] = true
+			duplicates[i.GAV()]++
+		}
+	}
+	seen := map[string]int{}
+	for i, a := range r.Spec.Artifacts {
+		path := specPath.Child("artifacts").Index(i)
+		gav := a.GAV()
+		seen[gav]++
+		if seen[gav] > 1 {
+			if seen[gav] > duplicates[gav] {
+				allErrs = append(allErrs, field.Duplicate(path, gav))
+			}
+			continue
+		}
+		if existing[a] {
+			continue
+		}
+		allErrs = append(allErrs, validateArtifact(path, a)...)
+	}
+	if r.Spec.SCMURL != "" && (old == nil || old.Spec.SCMURL != r.Spec.SCMURL) {
+		if err := ValidateSCMURL(r.Spec.SCMURL); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("scmURL"), r.Spec.SCMURL, err.Error()))
+		}
+	}
+	if r.Spec.PRURL != "" && (old == nil || old.Spec.PRURL != r.Spec.PRURL) {
+		if err := ValidateHTTPURL(r.Spec.PRURL); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("prURL"), r.Spec.PRURL, err.Error()))
+		}
+	}
+	if r.Spec.RetriggerTarget != "" {
+		for _, msg := range validation.IsDNS1123Label(r.Spec.RetriggerTarget) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("retriggerTarget"), r.Spec.RetriggerTarget, msg))
+		}
+	}
+	return allErrs
+}
+
+func validateRetriggerTarget(path *field.Path, t *RetriggerTarget) field.ErrorList {
+	var allErrs field.ErrorList
+	switch t.Type {
+	case RetriggerJenkins, RetriggerWebhook:
+		if err := ValidateHTTPURL(t.URL); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("url"), t.URL, err.Error()))
+		}
+	case RetriggerTekton:
+		if t.PipelineRunTemplate == "" {
+			allErrs = append(allErrs, field.Required(path.Child("pipelineRunTemplate"), "is required for Tekton"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), t.Type, []string{RetriggerJenkins, RetriggerWebhook, RetriggerTekton}))
+	}
+	return allErrs
+}
+
+func validateArtifact(path *field.Path, a ArtifactSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if !mavenIdentifier.MatchString(a.Group) {
+		allErrs = append(allErrs, field.Invalid(path.Child("group"), a.Group, "may only contain letters, digits, '_', '-' and '.'"))
+	}
+	if !mavenIdentifier.MatchString(a.Artifact) {
+		allErrs = append(allErrs, field.Invalid(path.Child("artifact"), a.Artifact, "may only contain letters, digits, '_', '-' and '.'"))
+	}
+	if !mavenVersion.MatchString(a.Version) {
+		allErrs = append(allErrs, field.Invalid(path.Child("version"), a.Version, "must not be empty or contain whitespace, ':', '/' or '\\'"))
+	}
+	if a.Classifier != "" && !mavenIdentifier.MatchString(a.Classifier) {
+		allErrs = append(allErrs, field.Invalid(path.Child("classifier"), a.Classifier, "may only contain letters, digits, '_', '-' and '.'"))
+	}
+	if a.Type != "" && !mavenIdentifier.MatchString(a.Type) {
+		allErrs = append(allErrs, field.Invalid(path.Child("type"), a.Type, "may only contain letters, digits, '_', '-' and '.'"))
+	}
+	if a.Scope != "" && !mavenScopes[a.Scope] {
+		allErrs = append(allErrs, field.NotSupported(path.Child("scope"), a.Scope, []string{"compile", "provided", "runtime", "test", "system", "import"}))
+	}
+	return allErrs
+}
+
+func (r *ComponentBuild) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(Kind("ComponentBuild"), r.Name, allErrs)
+}
+
+// ValidateGAV checks that the given string is a groupId:artifactId:version maven coordinate
+func ValidateGAV(gav string) error {
+	a := ParseGAV(gav)
+	if a.Group == "" {
+		return fmt.Errorf("must be in the form groupId:artifactId:version")
+	}
+	if !mavenIdentifier.MatchString(a.Group) {
+		return fmt.Errorf("groupId %q may only contain letters, digits, '_', '-' and '.'", a.Group)
+	}
+	if !mavenIdentifier.MatchString(a.Artifact) {
+		return fmt.Errorf("artifactId %q may only contain letters, digits, '_', '-' and '.'", a.Artifact)
+	}
+	if !mavenVersion.MatchString(a.Version) {
+		return fmt.Errorf("version %q must not be empty or contain whitespace, ':', '/' or '\\'", a.Version)
+	}
+	return nil
+}
+
+// ValidateSCMURL checks that the given string is a git repository URL, scp style git@host:path URLs are also accepted
+func ValidateSCMURL(scmURL string) error {
+	if scpLikeURL.MatchString(scmURL) {
+		return nil
+	}
+	u, err := url.Parse(scmURL)
+	if err != nil {
+		return fmt.Errorf("must be a valid URL: %s", err.Error())
+	}
+	switch u.Scheme {
+	case "http", "https", "ssh", "git":
+	default:
+		return fmt.Errorf("must be an http, https, ssh or git URL")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("must be an absolute URL including a host")
+	}
+	return nil
+}
+
+// ValidateHTTPURL checks that the given string is an absolute http or https URL
+func ValidateHTTPURL(httpURL string) error {
+	u, err := url.Parse(httpURL)
+	if err != nil {
+		return fmt.Errorf("must be a valid URL: %s", err.Error())
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("must be an http or https URL")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("must be an absolute URL including a host")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package v1alpha2
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func validComponentBuild() *ComponentBuild {
+	return &ComponentBuild{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: ComponentBuildSpec{
+			SCMURL: "https://github.com/test/test.git",
+			Tag:    "1.0",
+			Artifacts: []ArtifactSpec{
+				{Group: "com.test", Artifact: "test", Version: "1.0", Type: "jar", Scope: "compile"},
+				{Group: "com.test", Artifact: "test-tests", Version: "1.0", Classifier: "tests"},
+			},
+		},
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(cb *ComponentBuild)
+		field  string
+	}{
+		{name: "valid"},
+		{name: "missing version", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts[0].Version = "" }, field: "spec.artifacts[0].version"},
+		{name: "bad group", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts[1].Group = "com/test" }, field: "spec.artifacts[1].group"},
+		{name: "bad scope", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts[0].Scope = "everywhere" }, field: "spec.artifacts[0].scope"},
+		{name: "duplicate", modify: func(cb *ComponentBuild) { cb.Spec.Artifacts = append(cb.Spec.Artifacts, ParseGAV("com.test:test:1.0")) }, field: "spec.artifacts[2]"},
+		{name: "pr url", modify: func(cb *ComponentBuild) { cb.Spec.PRURL = "not a url" }, field: "spec.prURL"},
+		{name: "retrigger target", modify: func(cb *ComponentBuild) { cb.Spec.RetriggerTarget = "https://jenkins.example.com/job/test/build" }, field: "spec.retriggerTarget"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			cb := validComponentBuild()
+			if tt.modify != nil {
+				tt.modify(cb)
+			}
+			err := cb.ValidateCreate()
+			if tt.field == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(err.Error()).To(ContainSubstring(tt.field))
+		})
+	}
+}
+
+func TestValidateUpdateImmutableFields(t *testing.T) {
+	g := NewGomegaWithT(t)
+	old := validComponentBuild()
+	updated := validComponentBuild()
+	updated.Spec.Tag = "2.0"
+	err := updated.ValidateUpdate(old)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("spec.tag"))
+}
+
+func TestValidateUpdateExistingDuplicates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	//duplicates that predate validation do not block updates, the same as in v1alpha1
+	old := validComponentBuild()
+	old.Spec.Artifacts = append(old.Spec.Artifacts, ParseGAV("com.test:test:1.0"))
+	updated := old.DeepCopy()
+	updated.Labels = map[string]string{"test": "true"}
+	g.Expect(updated.ValidateUpdate(old)).To(Succeed())
+
+	//but another copy is rejected
+	updated.Spec.Artifacts = append(updated.Spec.Artifacts, ParseGAV("com.test:test:1.0"))
+	err := updated.ValidateUpdate(old)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("spec.artifacts[3]"))
+}
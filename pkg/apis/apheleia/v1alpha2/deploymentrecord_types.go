@@ -0,0 +1,66 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type DeploymentRecordSpec struct {
+	// RebuiltArtifact is the name of the RebuiltArtifact that is deployed
+	// +kubebuilder:validation:MinLength=1
+	RebuiltArtifact string `json:"rebuiltArtifact"`
+	// GAV is the group:artifact:version of the deployed artifact
+	GAV string `json:"gav,omitempty"`
+	// Target is the name of the DeploymentTarget the artifact is deployed to, or apheleia-config for namespaces that
+	// are still configured with the legacy config map
+	// +kubebuilder:validation:MinLength=1
+	Target string `json:"target"`
+}
+
+type DeploymentRecordStatus struct {
+	// Deployed is true once the artifact has been deployed to the target
+	Deployed bool `json:"deployed,omitempty"`
+	// DeployedTime is when the deploy completed
+	DeployedTime *metav1.Time `json:"deployedTime,omitempty"`
+	// Location is the repository the artifact was deployed to
+	Location string `json:"location,omitempty"`
+	// TaskRun is the name of the TaskRun that deployed the artifact, it is empty if the operator deployed it itself
+	TaskRun string `json:"taskRun,omitempty"`
+	// Checksums holds the SHA-1 of each deployed file, keyed by its path in the repository. For OCI repositories it
+	// holds the digest of the copied image instead. Deploys that run in a TaskRun do not record checksums.
+	Checksums map[string]string `json:"checksums,omitempty"`
+	// Attempts is the number of deploys that have failed
+	Attempts int `json:"attempts,omitempty"`
+	// LastFailure is the reason the last deploy failed
+	LastFailure string `json:"lastFailure,omitempty"`
+	// LastFailureTime is when the last deploy failed, failures that happened before it have already been counted
+	LastFailureTime *metav1.Time `json:"lastFailureTime,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=deploymentrecords,scope=Namespaced
+// +kubebuilder:printcolumn:name="GAV",type=string,JSONPath=`.spec.gav`
+// +kubebuilder:printcolumn:name="Target",type=string,JSONPath=`.spec.target`
+// +kubebuilder:printcolumn:name="Deployed",type=boolean,JSONPath=`.status.deployed`
+// +kubebuilder:printcolumn:name="Attempts",type=integer,JSONPath=`.status.attempts`,priority=1
+// +kubebuilder:printcolumn:name="TaskRun",type=string,JSONPath=`.status.taskRun`,priority=1
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+// DeploymentRecord Records the deployment of a RebuiltArtifact to a DeploymentTarget
+type DeploymentRecord struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeploymentRecordSpec   `json:"spec"`
+	Status DeploymentRecordStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeploymentRecordList contains a list of DeploymentRecord
+type DeploymentRecordList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeploymentRecord `json:"items"`
+}
@@ -0,0 +1,319 @@
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// RepositoryKindCodeArtifact deploys to AWS CodeArtifact using the apheleia-deploy ClusterTask
+	RepositoryKindCodeArtifact = "CodeArtifact"
+	// RepositoryKindMaven deploys to a Maven repository that accepts HTTP PUT, such as Nexus or Artifactory
+	RepositoryKindMaven = "Maven"
+	// RepositoryKindFilesystem deploys to a Maven repository layout on a volume mounted into the operator
+	RepositoryKindFilesystem = "Filesystem"
+	// RepositoryKindOCI copies the rebuilt artifact images to another OCI registry
+	RepositoryKindOCI = "OCI"
+)
+
+const (
+	// DeployModePerArtifact deploys every artifact on its own as soon as it has been built
+	DeployModePerArtifact = "PerArtifact"
+	// DeployModeBatched waits until every artifact in a ComponentBuild has been built and deploys them together
+	DeployModeBatched = "Batched"
+)
+
+const (
+	// DefaultCredentialsSecret is the secret that holds the AWS credentials if none is specified
+	DefaultCredentialsSecret = "aws-secrets"
+	// DefaultImageSecret is the secret that is used to pull rebuilt artifact images if none is specified
+	DefaultImageSecret = "jvm-build-image-secrets"
+	// DefaultMaxConcurrentDeploys is the number of artifacts that are deployed at once if no limit is specified
+	DefaultMaxConcurrentDeploys = 5
+	// DefaultMaxDeployAttempts is the number of times a deploy is attempted if no limit is specified
+	DefaultMaxDeployAttempts = 5
+)
+
+const (
+	// ArtifactRetentionRetain keeps ArtifactBuilds that are no longer referenced by any ComponentBuild, this is the
+	// default
+	ArtifactRetentionRetain = "Retain"
+	// ArtifactRetentionDelete deletes unreferenced ArtifactBuilds, along with the DependencyBuilds and
+	// RebuiltArtifacts that the build service created for them
+	ArtifactRetentionDelete = "Delete"
+)
+
+const (
+	NotifierGitHub  = "github"
+	NotifierGitLab  = "gitlab"
+	NotifierWebhook = "webhook"
+	NotifierTekton  = "tekton"
+)
+
+const (
+	// ConditionUsable is true when the target is valid and the secrets it references exist
+	ConditionUsable = "Usable"
+)
+
+const (
+	ReasonTargetValid   = "TargetValid"
+	ReasonInvalidSpec   = "InvalidSpec"
+	ReasonSecretMissing = "SecretMissing"
+)
+
+const (
+	// CodeArtifactAccessKey is the key in the credentials secret that holds the AWS access key
+	CodeArtifactAccessKey = "access-key"
+	// CodeArtifactSecretKey is the key in the credentials secret that holds the AWS secret key
+	CodeArtifactSecretKey = "secret-key"
+	// MavenUsernameKey is the key in the credentials secret that holds the Maven repository username
+	MavenUsernameKey = "username"
+	// MavenPasswordKey is the key in the credentials secret that holds the Maven repository password
+	MavenPasswordKey = "password"
+)
+
+type DeploymentTargetSpec struct {
+	// RepositoryURL is the URL of the maven repository that artifacts are deployed to, it is required for
+	// CodeArtifact and Maven repositories
+	RepositoryURL string `json:"repositoryURL,omitempty"`
+	// RepositoryKind is the type of repository that is deployed to
+	// +kubebuilder:validation:Enum=CodeArtifact;Maven;Filesystem;OCI
+	// +kubebuilder:default=CodeArtifact
+	RepositoryKind string `json:"repositoryKind,omitempty"`
+	// CredentialsSecret is the name of the secret holding the repository credentials. For CodeArtifact it must have
+	// access-key and secret-key keys and defaults to aws-secrets, for Maven it must have username and password keys,
+	// and for OCI it must be a docker config secret and defaults to the image secret.
+	CredentialsSecret string `json:"credentialsSecret,omitempty"`
+	// ImageSecret is the name of the docker config secret used to pull the rebuilt artifact images, defaults to
+	// jvm-build-image-secrets
+	ImageSecret string `json:"imageSecret,omitempty"`
+	// CodeArtifact holds the settings that are specific to AWS CodeArtifact repositories
+	CodeArtifact *CodeArtifactSettings `json:"codeArtifact,omitempty"`
+	// Filesystem holds the settings that are specific to Filesystem repositories
+	Filesystem *FilesystemSettings `json:"filesystem,omitempty"`
+	// OCI holds the settings that are specific to OCI repositories
+	OCI *OCISettings `json:"oci,omitempty"`
+	// MaxConcurrentDeploys is the number of artifacts in the namespace that are deployed at once, further artifacts
+	// are queued in the order they were built. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentDeploys int `json:"maxConcurrentDeploys,omitempty"`
+	// MaxDeployAttempts is the number of times deploying an artifact is attempted before it is marked as
+	// DeployFailed, failed attempts are retried with an exponential backoff. Defaults to 5.
+	// +kubebuilder:validation:Minimum=1
+	MaxDeployAttempts int `json:"maxDeployAttempts,omitempty"`
+	// DeployMode controls if artifacts are deployed one at a time as they are built, or in one batch per
+	// ComponentBuild once all its artifacts have been built. Batched is only supported for CodeArtifact.
+	// +kubebuilder:validation:Enum=PerArtifact;Batched
+	// +kubebuilder:default=PerArtifact
+	DeployMode string `json:"deployMode,omitempty"`
+	// Retention controls what is cleaned up in the namespace, by default nothing is
+	// +optional
+	Retention *RetentionSettings `json:"retention,omitempty"`
+	// Notification controls how the results of ComponentBuilds in the namespace are reported to their pull requests
+	// +optional
+	Notification *NotificationSettings `json:"notification,omitempty"`
+	// CloudEventsSink is the URL CloudEvents about the ComponentBuilds in the namespace are posted to, no events are
+	// emitted if it is not set
+	CloudEventsSink string `json:"cloudEventsSink,omitempty"`
+	// Retrigger is how a new build of the component is triggered once every artifact has been rebuilt and deployed,
+	// for ComponentBuilds that do not pick one of the RetriggerTargets
+	// +optional
+	Retrigger *RetriggerTarget `json:"retrigger,omitempty"`
+	// RetriggerTargets are the targets that ComponentBuilds can pick by name with their retriggerTarget
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	RetriggerTargets []NamedRetriggerTarget `json:"retriggerTargets,omitempty"`
+}
+
+// NamedRetriggerTarget is a retrigger target that ComponentBuilds refer to by name
+type NamedRetriggerTarget struct {
+	Name            string `json:"name"`
+	RetriggerTarget `json:",inline"`
+}
+
+type CodeArtifactSettings struct {
+	// +kubebuilder:validation:MinLength=1
+	Domain string `json:"domain"`
+	// +kubebuilder:validation:MinLength=1
+	Owner string `json:"owner"`
+}
+
+type FilesystemSettings struct {
+	// Path is the directory the repository is written to, relative to the volume mounted at
+	// /var/lib/apheleia/repositories in the operator
+	// +kubebuilder:validation:MinLength=1
+	Path string `json:"path"`
+}
+
+type OCISettings struct {
+	// Repository is the image repository the rebuilt artifact images are copied to, e.g. quay.io/my-org/artifacts
+	// +kubebuilder:validation:MinLength=1
+	Repository string `json:"repository"`
+}
+
+type RetentionSettings struct {
+	// Artifacts decides what happens to ArtifactBuilds that are no longer referenced by any ComponentBuild
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	Artifacts string `json:"artifacts,omitempty"`
+	// ComponentBuildTTL is how long finished ComponentBuilds are kept, they are kept forever if it is not set
+	ComponentBuildTTL *metav1.Duration `json:"componentBuildTTL,omitempty"`
+	// KeepRuns is how many finished deploy TaskRuns and notify PipelineRuns are kept, all of them are kept if it is
+	// not set
+	// +kubebuilder:validation:Minimum=0
+	KeepRuns *int `json:"keepRuns,omitempty"`
+}
+
+type NotificationSettings struct {
+	// Provider selects how results are reported, if it is not set it is detected from the PR URL
+	// +kubebuilder:validation:Enum=github;gitlab;webhook;tekton
+	Provider string `json:"provider,omitempty"`
+	// URL is the URL of the webhook, or the API URL of a GitHub or GitLab server if it cannot be derived from the PR
+	// URL
+	URL string `json:"url,omitempty"`
+	// Secret is the name of the secret holding the notifier credentials, defaults to the git credentials of the
+	// namespace
+	Secret string `json:"secret,omitempty"`
+	// SuccessTemplate is the Go template for the message of a build that completed
+	SuccessTemplate string `json:"successTemplate,omitempty"`
+	// FailureTemplate is the Go template for the message of a build that failed
+	FailureTemplate string `json:"failureTemplate,omitempty"`
+	// ProgressTemplate is the Go template for the message of a build that is still in progress, which is only shown
+	// in progress comments
+	ProgressTemplate string `json:"progressTemplate,omitempty"`
+}
+
+type DeploymentTargetStatus struct {
+	// ObservedGeneration is the most recent generation of the spec the controller has acted on
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// Conditions represent the latest available observations of the target
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:storageversion
+// +kubebuilder:resource:path=deploymenttargets,scope=Namespaced
+// +kubebuilder:printcolumn:name="Kind",type=string,JSONPath=`.spec.repositoryKind`
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.repositoryURL`
+// +kubebuilder:printcolumn:name="Path",type=string,JSONPath=`.spec.filesystem.path`,priority=1
+// +kubebuilder:printcolumn:name="Repository",type=string,JSONPath=`.spec.oci.repository`,priority=1
+// +kubebuilder:printcolumn:name="Usable",type=string,JSONPath=`.status.conditions[?(@.type=="Usable")].status`
+// +kubebuilder:printcolumn:name="Message",type=string,JSONPath=`.status.conditions[?(@.type=="Usable")].message`
+// DeploymentTarget A repository that rebuilt artifacts in the namespace are deployed to
+type DeploymentTarget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeploymentTargetSpec   `json:"spec"`
+	Status DeploymentTargetStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// DeploymentTargetList contains a list of DeploymentTarget
+type DeploymentTargetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DeploymentTarget `json:"items"`
+}
+
+// GetCredentialsSecret returns the name of the credentials secret, taking the defaults into account. An empty
+// string means that no credentials are used.
+func (s *DeploymentTargetSpec) GetCredentialsSecret() string {
+	if s.CredentialsSecret != "" {
+		return s.CredentialsSecret
+	}
+	switch s.GetRepositoryKind() {
+	case RepositoryKindCodeArtifact:
+		return DefaultCredentialsSecret
+	case RepositoryKindOCI:
+		return s.GetImageSecret()
+	}
+	return ""
+}
+
+// GetImageSecret returns the name of the image pull secret, taking the default into account
+func (s *DeploymentTargetSpec) GetImageSecret() string {
+	if s.ImageSecret == "" {
+		return DefaultImageSecret
+	}
+	return s.ImageSecret
+}
+
+// GetRepositoryKind returns the kind of repository, taking the default into account
+func (s *DeploymentTargetSpec) GetRepositoryKind() string {
+	if s.RepositoryKind == "" {
+		return RepositoryKindCodeArtifact
+	}
+	return s.RepositoryKind
+}
+
+// GetMaxConcurrentDeploys returns the deploy concurrency limit, taking the default into account
+func (s *DeploymentTargetSpec) GetMaxConcurrentDeploys() int {
+	if s.MaxConcurrentDeploys <= 0 {
+		return DefaultMaxConcurrentDeploys
+	}
+	return s.MaxConcurrentDeploys
+}
+
+// GetMaxDeployAttempts returns the number of deploy attempts, taking the default into account
+func (s *DeploymentTargetSpec) GetMaxDeployAttempts() int {
+	if s.MaxDeployAttempts <= 0 {
+		return DefaultMaxDeployAttempts
+	}
+	return s.MaxDeployAttempts
+}
+
+// GetDeployMode returns the deploy mode, taking the default into account
+func (s *DeploymentTargetSpec) GetDeployMode() string {
+	if s.DeployMode == "" {
+		return DeployModePerArtifact
+	}
+	return s.DeployMode
+}
+
+// GetRetention returns the retention settings, which are empty if none are specified
+func (s *DeploymentTargetSpec) GetRetention() RetentionSettings {
+	if s.Retention == nil {
+		return RetentionSettings{}
+	}
+	return *s.Retention
+}
+
+// GetNotification returns the notification settings, which are empty if none are specified
+func (s *DeploymentTargetSpec) GetNotification() NotificationSettings {
+	if s.Notification == nil {
+		return NotificationSettings{}
+	}
+	return *s.Notification
+}
+
+// GetRetriggerTarget returns the named retrigger target, or the default one if the name is empty. It returns nil if
+// there is no such target.
+func (s *DeploymentTargetSpec) GetRetriggerTarget(name string) *RetriggerTarget {
+	if name == "" {
+		return s.Retrigger
+	}
+	for i := range s.RetriggerTargets {
+		if s.RetriggerTargets[i].Name == name {
+			return &s.RetriggerTargets[i].RetriggerTarget
+		}
+	}
+	return nil
+}
+
+// Location returns a human readable description of where artifacts are deployed to
+func (s *DeploymentTargetSpec) Location() string {
+	switch {
+	case s.GetRepositoryKind() == RepositoryKindFilesystem && s.Filesystem != nil:
+		return s.Filesystem.Path
+	case s.GetRepositoryKind() == RepositoryKindOCI && s.OCI != nil:
+		return s.OCI.Repository
+	}
+	return s.RepositoryURL
+}
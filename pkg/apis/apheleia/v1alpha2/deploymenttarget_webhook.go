@@ -0,0 +1,180 @@
+package v1alpha2
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (r *DeploymentTarget) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(r).Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-apheleia-io-v1alpha2-deploymenttarget,mutating=false,failurePolicy=fail,sideEffects=None,groups=apheleia.io,resources=deploymenttargets,verbs=create;update,versions=v1alpha2,name=vdeploymenttarget.apheleia.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &DeploymentTarget{}
+
+// ValidateCreate implements webhook.Validator
+func (r *DeploymentTarget) ValidateCreate() error {
+	return r.invalid(r.Spec.Validate(field.NewPath("spec")))
+}
+
+// ValidateUpdate implements webhook.Validator
+func (r *DeploymentTarget) ValidateUpdate(old runtime.Object) error {
+	if _, ok := old.(*DeploymentTarget); !ok {
+		return apierrors.NewBadRequest(fmt.Sprintf("expected a DeploymentTarget but got a %T", old))
+	}
+	if r.DeletionTimestamp != nil {
+		return nil
+	}
+	return r.invalid(r.Spec.Validate(field.NewPath("spec")))
+}
+
+// ValidateDelete implements webhook.Validator
+func (r *DeploymentTarget) ValidateDelete() error {
+	return nil
+}
+
+// Validate checks the spec, it is also used by the controller so targets that were created while the webhook
+// was not installed are still reported as unusable
+func (s *DeploymentTargetSpec) Validate(specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	switch s.GetRepositoryKind() {
+	case RepositoryKindCodeArtifact:
+		allErrs = append(allErrs, s.validateRepositoryURL(specPath)...)
+		if s.CodeArtifact == nil {
+			allErrs = append(allErrs, field.Required(specPath.Child("codeArtifact"), "is required for CodeArtifact repositories"))
+		} else {
+			if s.CodeArtifact.Domain == "" {
+				allErrs = append(allErrs, field.Required(specPath.Child("codeArtifact", "domain"), ""))
+			}
+			if s.CodeArtifact.Owner == "" {
+				allErrs = append(allErrs, field.Required(specPath.Child("codeArtifact", "owner"), ""))
+			}
+		}
+	case RepositoryKindMaven:
+		allErrs = append(allErrs, s.validateRepositoryURL(specPath)...)
+	case RepositoryKindFilesystem:
+		if s.Filesystem == nil {
+			allErrs = append(allErrs, field.Required(specPath.Child("filesystem"), "is required for Filesystem repositories"))
+		} else if s.Filesystem.Path == "" || path.IsAbs(s.Filesystem.Path) || strings.HasPrefix(path.Clean(s.Filesystem.Path), "..") {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("filesystem", "path"), s.Filesystem.Path, "must be a relative path inside the repositories volume"))
+		}
+	case RepositoryKindOCI:
+		if s.OCI == nil {
+			allErrs = append(allErrs, field.Required(specPath.Child("oci"), "is required for OCI repositories"))
+		} else if _, err := name.NewRepository(s.OCI.Repository); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("oci", "repository"), s.OCI.Repository, err.Error()))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("repositoryKind"), s.RepositoryKind, []string{RepositoryKindCodeArtifact, RepositoryKindMaven, RepositoryKindFilesystem, RepositoryKindOCI}))
+	}
+	switch s.GetDeployMode() {
+	case DeployModePerArtifact:
+	case DeployModeBatched:
+		if s.GetRepositoryKind() != RepositoryKindCodeArtifact {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("deployMode"), s.DeployMode, "is only supported for CodeArtifact repositories"))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(specPath.Child("deployMode"), s.DeployMode, []string{DeployModePerArtifact, DeployModeBatched}))
+	}
+	if s.MaxConcurrentDeploys < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxConcurrentDeploys"), s.MaxConcurrentDeploys, "must be at least 1"))
+	}
+	if s.MaxDeployAttempts < 0 {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("maxDeployAttempts"), s.MaxDeployAttempts, "must be at least 1"))
+	}
+	for _, secret := range []struct{ name, value string }{{"credentialsSecret", s.CredentialsSecret}, {"imageSecret", s.ImageSecret}} {
+		if secret.value == "" {
+			continue
+		}
+		for _, msg := range validation.IsDNS1123Subdomain(secret.value) {
+			allErrs = append(allErrs, field.Invalid(specPath.Child(secret.name), secret.value, msg))
+		}
+	}
+	allErrs = append(allErrs, s.validateSettings(specPath)...)
+	return allErrs
+}
+
+// validateSettings checks the settings that are not about deploying
+func (s *DeploymentTargetSpec) validateSettings(specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if r := s.Retention; r != nil {
+		switch r.Artifacts {
+		case "", ArtifactRetentionRetain, ArtifactRetentionDelete:
+		default:
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("retention", "artifacts"), r.Artifacts, []string{ArtifactRetentionRetain, ArtifactRetentionDelete}))
+		}
+		if r.ComponentBuildTTL != nil && r.ComponentBuildTTL.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("retention", "componentBuildTTL"), r.ComponentBuildTTL.Duration.String(), "must not be negative"))
+		}
+		if r.KeepRuns != nil && *r.KeepRuns < 0 {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("retention", "keepRuns"), *r.KeepRuns, "must not be negative"))
+		}
+	}
+	if n := s.Notification; n != nil {
+		switch n.Provider {
+		case "", NotifierGitHub, NotifierGitLab, NotifierTekton:
+		case NotifierWebhook:
+			if n.URL == "" {
+				allErrs = append(allErrs, field.Required(specPath.Child("notification", "url"), "is required for the webhook provider"))
+			}
+		default:
+			allErrs = append(allErrs, field.NotSupported(specPath.Child("notification", "provider"), n.Provider, []string{NotifierGitHub, NotifierGitLab, NotifierWebhook, NotifierTekton}))
+		}
+		if n.URL != "" {
+			if err := ValidateHTTPURL(n.URL); err != nil {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("notification", "url"), n.URL, err.Error()))
+			}
+		}
+		if n.Secret != "" {
+			for _, msg := range validation.IsDNS1123Subdomain(n.Secret) {
+				allErrs = append(allErrs, field.Invalid(specPath.Child("notification", "secret"), n.Secret, msg))
+			}
+		}
+	}
+	if s.CloudEventsSink != "" {
+		if err := ValidateHTTPURL(s.CloudEventsSink); err != nil {
+			allErrs = append(allErrs, field.Invalid(specPath.Child("cloudEventsSink"), s.CloudEventsSink, err.Error()))
+		}
+	}
+	if s.Retrigger != nil {
+		allErrs = append(allErrs, validateRetriggerTarget(specPath.Child("retrigger"), s.Retrigger)...)
+	}
+	names := map[string]bool{}
+	for i, t := range s.RetriggerTargets {
+		path := specPath.Child("retriggerTargets").Index(i)
+		for _, msg := range validation.IsDNS1123Label(t.Name) {
+			allErrs = append(allErrs, field.Invalid(path.Child("name"), t.Name, msg))
+		}
+		if names[t.Name] {
+			allErrs = append(allErrs, field.Duplicate(path.Child("name"), t.Name))
+		}
+		names[t.Name] = true
+		allErrs = append(allErrs, validateRetriggerTarget(path, &s.RetriggerTargets[i].RetriggerTarget)...)
+	}
+	return allErrs
+}
+
+func (s *DeploymentTargetSpec) validateRepositoryURL(specPath *field.Path) field.ErrorList {
+	if err := ValidateHTTPURL(s.RepositoryURL); err != nil {
+		return field.ErrorList{field.Invalid(specPath.Child("repositoryURL"), s.RepositoryURL, err.Error())}
+	}
+	return nil
+}
+
+func (r *DeploymentTarget) invalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(Kind("DeploymentTarget"), r.Name, allErrs)
+}
@@ -0,0 +1,109 @@
+package v1alpha2
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func validDeploymentTarget() *DeploymentTarget {
+	return &DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+		Spec: DeploymentTargetSpec{
+			RepositoryURL: "https://test-123456789012.d.codeartifact.us-east-1.amazonaws.com/maven/test/",
+			CodeArtifact:  &CodeArtifactSettings{Domain: "test", Owner: "123456789012"},
+		},
+	}
+}
+
+func TestValidateDeploymentTarget(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(dt *DeploymentTarget)
+		field  string
+	}{
+		{name: "valid"},
+		{name: "custom secrets", modify: func(dt *DeploymentTarget) {
+			dt.Spec.CredentialsSecret = "my-aws"
+			dt.Spec.ImageSecret = "my-images"
+		}},
+		{name: "missing url", modify: func(dt *DeploymentTarget) { dt.Spec.RepositoryURL = "" }, field: "spec.repositoryURL"},
+		{name: "unknown kind", modify: func(dt *DeploymentTarget) { dt.Spec.RepositoryKind = "Nexus" }, field: "spec.repositoryKind"},
+		{name: "missing code artifact", modify: func(dt *DeploymentTarget) { dt.Spec.CodeArtifact = nil }, field: "spec.codeArtifact"},
+		{name: "missing owner", modify: func(dt *DeploymentTarget) { dt.Spec.CodeArtifact.Owner = "" }, field: "spec.codeArtifact.owner"},
+		{name: "maven", modify: func(dt *DeploymentTarget) {
+			dt.Spec.RepositoryKind = RepositoryKindMaven
+			dt.Spec.CodeArtifact = nil
+		}},
+		{name: "maven missing url", modify: func(dt *DeploymentTarget) {
+			dt.Spec.RepositoryKind = RepositoryKindMaven
+			dt.Spec.RepositoryURL = ""
+		}, field: "spec.repositoryURL"},
+		{name: "filesystem", modify: func(dt *DeploymentTarget) {
+			dt.Spec = DeploymentTargetSpec{RepositoryKind: RepositoryKindFilesystem, Filesystem: &FilesystemSettings{Path: "mirror/maven"}}
+		}},
+		{name: "filesystem escapes volume", modify: func(dt *DeploymentTarget) {
+			dt.Spec = DeploymentTargetSpec{RepositoryKind: RepositoryKindFilesystem, Filesystem: &FilesystemSettings{Path: "mirror/../../etc"}}
+		}, field: "spec.filesystem.path"},
+		{name: "oci", modify: func(dt *DeploymentTarget) {
+			dt.Spec = DeploymentTargetSpec{RepositoryKind: RepositoryKindOCI, OCI: &OCISettings{Repository: "quay.io/test/artifacts"}}
+		}},
+		{name: "oci bad repository", modify: func(dt *DeploymentTarget) {
+			dt.Spec = DeploymentTargetSpec{RepositoryKind: RepositoryKindOCI, OCI: &OCISettings{Repository: "quay.io/Test/artifacts:latest"}}
+		}, field: "spec.oci.repository"},
+		{name: "batched", modify: func(dt *DeploymentTarget) { dt.Spec.DeployMode = DeployModeBatched }},
+		{name: "settings", modify: func(dt *DeploymentTarget) {
+			keepRuns := 10
+			dt.Spec.Retention = &RetentionSettings{Artifacts: ArtifactRetentionDelete, ComponentBuildTTL: &metav1.Duration{Duration: time.Hour}, KeepRuns: &keepRuns}
+			dt.Spec.Notification = &NotificationSettings{Provider: NotifierWebhook, URL: "https://hooks.example.com/builds", Secret: "webhook-token"}
+			dt.Spec.CloudEventsSink = "http://broker.example.com/default"
+			dt.Spec.Retrigger = &RetriggerTarget{Type: RetriggerJenkins, URL: "https://jenkins.example.com/job/test/build"}
+			dt.Spec.RetriggerTargets = []NamedRetriggerTarget{{Name: "tekton", RetriggerTarget: RetriggerTarget{Type: RetriggerTekton, PipelineRunTemplate: "spec: {}"}}}
+		}},
+		{name: "negative keep runs", modify: func(dt *DeploymentTarget) {
+			keepRuns := -1
+			dt.Spec.Retention = &RetentionSettings{KeepRuns: &keepRuns}
+		}, field: "spec.retention.keepRuns"},
+		{name: "webhook without url", modify: func(dt *DeploymentTarget) {
+			dt.Spec.Notification = &NotificationSettings{Provider: NotifierWebhook}
+		}, field: "spec.notification.url"},
+		{name: "bad sink", modify: func(dt *DeploymentTarget) { dt.Spec.CloudEventsSink = "broker:8080" }, field: "spec.cloudEventsSink"},
+		{name: "bad retrigger", modify: func(dt *DeploymentTarget) {
+			dt.Spec.Retrigger = &RetriggerTarget{Type: RetriggerTekton}
+		}, field: "spec.retrigger.pipelineRunTemplate"},
+		{name: "duplicate retrigger target", modify: func(dt *DeploymentTarget) {
+			target := NamedRetriggerTarget{Name: "jenkins", RetriggerTarget: RetriggerTarget{Type: RetriggerJenkins, URL: "https://jenkins.example.com/job/test/build"}}
+			dt.Spec.RetriggerTargets = []NamedRetriggerTarget{target, target}
+		}, field: "spec.retriggerTargets[1].name"},
+		{name: "bad named retrigger", modify: func(dt *DeploymentTarget) {
+			dt.Spec.RetriggerTargets = []NamedRetriggerTarget{{Name: "jenkins", RetriggerTarget: RetriggerTarget{Type: RetriggerJenkins}}}
+		}, field: "spec.retriggerTargets[0].url"},
+		{name: "batched maven", modify: func(dt *DeploymentTarget) {
+			dt.Spec.RepositoryKind = RepositoryKindMaven
+			dt.Spec.DeployMode = DeployModeBatched
+		}, field: "spec.deployMode"},
+		{name: "unknown deploy mode", modify: func(dt *DeploymentTarget) { dt.Spec.DeployMode = "Nightly" }, field: "spec.deployMode"},
+		{name: "negative attempts", modify: func(dt *DeploymentTarget) { dt.Spec.MaxDeployAttempts = -1 }, field: "spec.maxDeployAttempts"},
+		{name: "negative concurrency", modify: func(dt *DeploymentTarget) { dt.Spec.MaxConcurrentDeploys = -1 }, field: "spec.maxConcurrentDeploys"},
+		{name: "bad secret name", modify: func(dt *DeploymentTarget) { dt.Spec.CredentialsSecret = "My_Secret" }, field: "spec.credentialsSecret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			dt := validDeploymentTarget()
+			if tt.modify != nil {
+				tt.modify(dt)
+			}
+			err := dt.ValidateCreate()
+			if tt.field == "" {
+				g.Expect(err).NotTo(HaveOccurred())
+				return
+			}
+			g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+			g.Expect(err.Error()).To(ContainSubstring(tt.field))
+		})
+	}
+}
@@ -0,0 +1,4 @@
+// Package v1alpha2 contains API Schema definitions for the apheleia v1alpha2 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=apheleia.io
+package v1alpha2
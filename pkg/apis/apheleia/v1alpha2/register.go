@@ -0,0 +1,47 @@
+// Package v1alpha2 contains API Schema definitions for the apheleia v1alpha2 API group
+// +k8s:deepcopy-gen=package,register
+// +groupName=apheleia.io
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// SchemeGroupVersion is group version used to register these objects
+var SchemeGroupVersion = schema.GroupVersion{Group: "apheleia.io", Version: "v1alpha2"}
+
+// Kind takes an unqualified kind and returns back a Group qualified GroupKind
+func Kind(kind string) schema.GroupKind {
+	return SchemeGroupVersion.WithKind(kind).GroupKind()
+}
+
+// Resource takes an unqualified resource and returns a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
+var (
+	SchemeBuilder = runtime.NewSchemeBuilder(addKnownTypes)
+
+	// AddToScheme adds Build types to the scheme.
+	AddToScheme = SchemeBuilder.AddToScheme
+)
+
+// Adds the list of known types to Scheme.
+func addKnownTypes(scheme *runtime.Scheme) error {
+	scheme.AddKnownTypes(SchemeGroupVersion,
+		&ComponentBuild{},
+		&ComponentBuildList{},
+		&DeploymentTarget{},
+		&DeploymentTargetList{},
+		&DeploymentRecord{},
+		&DeploymentRecordList{},
+	)
+	// &Condition{},
+	// &ConditionList{},
+
+	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
+	return nil
+}
@@ -0,0 +1,633 @@
+//go:build !ignore_autogenerated
+// +build !ignore_autogenerated
+
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by deepcopy-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactSpec) DeepCopyInto(out *ArtifactSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactSpec.
+func (in *ArtifactSpec) DeepCopy() *ArtifactSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ArtifactState) DeepCopyInto(out *ArtifactState) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ArtifactState.
+func (in *ArtifactState) DeepCopy() *ArtifactState {
+	if in == nil {
+		return nil
+	}
+	out := new(ArtifactState)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEvent) DeepCopyInto(out *CloudEvent) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEvent.
+func (in *CloudEvent) DeepCopy() *CloudEvent {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEvent)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CodeArtifactSettings) DeepCopyInto(out *CodeArtifactSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodeArtifactSettings.
+func (in *CodeArtifactSettings) DeepCopy() *CodeArtifactSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(CodeArtifactSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommitStatus) DeepCopyInto(out *CommitStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommitStatus.
+func (in *CommitStatus) DeepCopy() *CommitStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CommitStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentBuild) DeepCopyInto(out *ComponentBuild) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentBuild.
+func (in *ComponentBuild) DeepCopy() *ComponentBuild {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentBuild)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComponentBuild) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentBuildList) DeepCopyInto(out *ComponentBuildList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ComponentBuild, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentBuildList.
+func (in *ComponentBuildList) DeepCopy() *ComponentBuildList {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentBuildList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ComponentBuildList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentBuildSpec) DeepCopyInto(out *ComponentBuildSpec) {
+	*out = *in
+	if in.Artifacts != nil {
+		in, out := &in.Artifacts, &out.Artifacts
+		*out = make([]ArtifactSpec, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentBuildSpec.
+func (in *ComponentBuildSpec) DeepCopy() *ComponentBuildSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentBuildSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentBuildStatus) DeepCopyInto(out *ComponentBuildStatus) {
+	*out = *in
+	if in.ArtifactState != nil {
+		in, out := &in.ArtifactState, &out.ArtifactState
+		*out = make([]ArtifactState, len(*in))
+		copy(*out, *in)
+	}
+	if in.CommitStatus != nil {
+		in, out := &in.CommitStatus, &out.CommitStatus
+		*out = new(CommitStatus)
+		**out = **in
+	}
+	if in.ProgressComment != nil {
+		in, out := &in.ProgressComment, &out.ProgressComment
+		*out = new(PullRequestComment)
+		**out = **in
+	}
+	if in.PendingEvents != nil {
+		in, out := &in.PendingEvents, &out.PendingEvents
+		*out = make([]CloudEvent, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Retrigger != nil {
+		in, out := &in.Retrigger, &out.Retrigger
+		*out = new(RetriggerStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentBuildStatus.
+func (in *ComponentBuildStatus) DeepCopy() *ComponentBuildStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentBuildStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentRecord) DeepCopyInto(out *DeploymentRecord) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentRecord.
+func (in *DeploymentRecord) DeepCopy() *DeploymentRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentRecord) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentRecordList) DeepCopyInto(out *DeploymentRecordList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeploymentRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentRecordList.
+func (in *DeploymentRecordList) DeepCopy() *DeploymentRecordList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentRecordList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentRecordList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentRecordSpec) DeepCopyInto(out *DeploymentRecordSpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentRecordSpec.
+func (in *DeploymentRecordSpec) DeepCopy() *DeploymentRecordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentRecordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentRecordStatus) DeepCopyInto(out *DeploymentRecordStatus) {
+	*out = *in
+	if in.DeployedTime != nil {
+		in, out := &in.DeployedTime, &out.DeployedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Checksums != nil {
+		in, out := &in.Checksums, &out.Checksums
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastFailureTime != nil {
+		in, out := &in.LastFailureTime, &out.LastFailureTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentRecordStatus.
+func (in *DeploymentRecordStatus) DeepCopy() *DeploymentRecordStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentRecordStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentTarget) DeepCopyInto(out *DeploymentTarget) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTarget.
+func (in *DeploymentTarget) DeepCopy() *DeploymentTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentTarget) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentTargetList) DeepCopyInto(out *DeploymentTargetList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DeploymentTarget, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTargetList.
+func (in *DeploymentTargetList) DeepCopy() *DeploymentTargetList {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentTargetList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DeploymentTargetList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentTargetSpec) DeepCopyInto(out *DeploymentTargetSpec) {
+	*out = *in
+	if in.CodeArtifact != nil {
+		in, out := &in.CodeArtifact, &out.CodeArtifact
+		*out = new(CodeArtifactSettings)
+		**out = **in
+	}
+	if in.Filesystem != nil {
+		in, out := &in.Filesystem, &out.Filesystem
+		*out = new(FilesystemSettings)
+		**out = **in
+	}
+	if in.OCI != nil {
+		in, out := &in.OCI, &out.OCI
+		*out = new(OCISettings)
+		**out = **in
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RetentionSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notification != nil {
+		in, out := &in.Notification, &out.Notification
+		*out = new(NotificationSettings)
+		**out = **in
+	}
+	if in.Retrigger != nil {
+		in, out := &in.Retrigger, &out.Retrigger
+		*out = new(RetriggerTarget)
+		**out = **in
+	}
+	if in.RetriggerTargets != nil {
+		in, out := &in.RetriggerTargets, &out.RetriggerTargets
+		*out = make([]NamedRetriggerTarget, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTargetSpec.
+func (in *DeploymentTargetSpec) DeepCopy() *DeploymentTargetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentTargetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentTargetStatus) DeepCopyInto(out *DeploymentTargetStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentTargetStatus.
+func (in *DeploymentTargetStatus) DeepCopy() *DeploymentTargetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentTargetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FilesystemSettings) DeepCopyInto(out *FilesystemSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FilesystemSettings.
+func (in *FilesystemSettings) DeepCopy() *FilesystemSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(FilesystemSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamedRetriggerTarget) DeepCopyInto(out *NamedRetriggerTarget) {
+	*out = *in
+	out.RetriggerTarget = in.RetriggerTarget
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamedRetriggerTarget.
+func (in *NamedRetriggerTarget) DeepCopy() *NamedRetriggerTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(NamedRetriggerTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NotificationSettings) DeepCopyInto(out *NotificationSettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NotificationSettings.
+func (in *NotificationSettings) DeepCopy() *NotificationSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OCISettings) DeepCopyInto(out *OCISettings) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OCISettings.
+func (in *OCISettings) DeepCopy() *OCISettings {
+	if in == nil {
+		return nil
+	}
+	out := new(OCISettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PullRequestComment) DeepCopyInto(out *PullRequestComment) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PullRequestComment.
+func (in *PullRequestComment) DeepCopy() *PullRequestComment {
+	if in == nil {
+		return nil
+	}
+	out := new(PullRequestComment)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionSettings) DeepCopyInto(out *RetentionSettings) {
+	*out = *in
+	if in.ComponentBuildTTL != nil {
+		in, out := &in.ComponentBuildTTL, &out.ComponentBuildTTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.KeepRuns != nil {
+		in, out := &in.KeepRuns, &out.KeepRuns
+		*out = new(int)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionSettings.
+func (in *RetentionSettings) DeepCopy() *RetentionSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetriggerStatus) DeepCopyInto(out *RetriggerStatus) {
+	*out = *in
+	if in.TriggeredTime != nil {
+		in, out := &in.TriggeredTime, &out.TriggeredTime
+		*out = (*in).DeepCopy()
+	}
+	if in.LastAttemptTime != nil {
+		in, out := &in.LastAttemptTime, &out.LastAttemptTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetriggerStatus.
+func (in *RetriggerStatus) DeepCopy() *RetriggerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RetriggerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetriggerTarget) DeepCopyInto(out *RetriggerTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetriggerTarget.
+func (in *RetriggerTarget) DeepCopy() *RetriggerTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(RetriggerTarget)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -22,6 +22,7 @@ import (
 	"net/http"
 
 	apheleiav1alpha1 "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/typed/apheleia/v1alpha1"
+	apheleiav1alpha2 "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/typed/apheleia/v1alpha2"
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
 	flowcontrol "k8s.io/client-go/util/flowcontrol"
@@ -30,6 +31,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	ApheleiaV1alpha1() apheleiav1alpha1.ApheleiaV1alpha1Interface
+	ApheleiaV1alpha2() apheleiav1alpha2.ApheleiaV1alpha2Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -37,6 +39,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	apheleiaV1alpha1 *apheleiav1alpha1.ApheleiaV1alpha1Client
+	apheleiaV1alpha2 *apheleiav1alpha2.ApheleiaV1alpha2Client
 }
 
 // ApheleiaV1alpha1 retrieves the ApheleiaV1alpha1Client
@@ -44,6 +47,11 @@ func (c *Clientset) ApheleiaV1alpha1() apheleiav1alpha1.ApheleiaV1alpha1Interfac
 	return c.apheleiaV1alpha1
 }
 
+// ApheleiaV1alpha2 retrieves the ApheleiaV1alpha2Client
+func (c *Clientset) ApheleiaV1alpha2() apheleiav1alpha2.ApheleiaV1alpha2Interface {
+	return c.apheleiaV1alpha2
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -92,6 +100,10 @@ func NewForConfigAndClient(c *rest.Config, httpClient *http.Client) (*Clientset,
 	if err != nil {
 		return nil, err
 	}
+	cs.apheleiaV1alpha2, err = apheleiav1alpha2.NewForConfigAndClient(&configShallowCopy, httpClient)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfigAndClient(&configShallowCopy, httpClient)
 	if err != nil {
@@ -114,6 +126,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.apheleiaV1alpha1 = apheleiav1alpha1.New(c)
+	cs.apheleiaV1alpha2 = apheleiav1alpha2.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -21,6 +21,8 @@ import (
 	clientset "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned"
 	apheleiav1alpha1 "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/typed/apheleia/v1alpha1"
 	fakeapheleiav1alpha1 "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/typed/apheleia/v1alpha1/fake"
+	apheleiav1alpha2 "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/typed/apheleia/v1alpha2"
+	fakeapheleiav1alpha2 "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/typed/apheleia/v1alpha2/fake"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
@@ -82,3 +84,8 @@ var (
 func (c *Clientset) ApheleiaV1alpha1() apheleiav1alpha1.ApheleiaV1alpha1Interface {
 	return &fakeapheleiav1alpha1.FakeApheleiaV1alpha1{Fake: &c.Fake}
 }
+
+// ApheleiaV1alpha2 retrieves the ApheleiaV1alpha2Client
+func (c *Clientset) ApheleiaV1alpha2() apheleiav1alpha2.ApheleiaV1alpha2Interface {
+	return &fakeapheleiav1alpha2.FakeApheleiaV1alpha2{Fake: &c.Fake}
+}
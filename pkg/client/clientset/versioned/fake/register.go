@@ -19,6 +19,7 @@ package fake
 
 import (
 	apheleiav1alpha1 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha1"
+	apheleiav1alpha2 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
@@ -31,6 +32,7 @@ var codecs = serializer.NewCodecFactory(scheme)
 
 var localSchemeBuilder = runtime.SchemeBuilder{
 	apheleiav1alpha1.AddToScheme,
+	apheleiav1alpha2.AddToScheme,
 }
 
 // AddToScheme adds all types of this clientset into the given scheme. This allows composition
@@ -0,0 +1,194 @@
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"context"
+	"time"
+
+	v1alpha2 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	scheme "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// ComponentBuildsGetter has a method to return a ComponentBuildInterface.
+// A group's client should implement this interface.
+type ComponentBuildsGetter interface {
+	ComponentBuilds(namespace string) ComponentBuildInterface
+}
+
+// ComponentBuildInterface has methods to work with ComponentBuild resources.
+type ComponentBuildInterface interface {
+	Create(ctx context.Context, componentBuild *v1alpha2.ComponentBuild, opts v1.CreateOptions) (*v1alpha2.ComponentBuild, error)
+	Update(ctx context.Context, componentBuild *v1alpha2.ComponentBuild, opts v1.UpdateOptions) (*v1alpha2.ComponentBuild, error)
+	UpdateStatus(ctx context.Context, componentBuild *v1alpha2.ComponentBuild, opts v1.UpdateOptions) (*v1alpha2.ComponentBuild, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha2.ComponentBuild, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha2.ComponentBuildList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.ComponentBuild, err error)
+	ComponentBuildExpansion
+}
+
+// componentBuilds implements ComponentBuildInterface
+type componentBuilds struct {
+	client rest.Interface
+	ns     string
+}
+
+// newComponentBuilds returns a ComponentBuilds
+func newComponentBuilds(c *ApheleiaV1alpha2Client, namespace string) *componentBuilds {
+	return &componentBuilds{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the componentBuild, and returns the corresponding componentBuild object, and an error if there is any.
+func (c *componentBuilds) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha2.ComponentBuild, err error) {
+	result = &v1alpha2.ComponentBuild{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of ComponentBuilds that match those selectors.
+func (c *componentBuilds) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha2.ComponentBuildList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha2.ComponentBuildList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested componentBuilds.
+func (c *componentBuilds) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a componentBuild and creates it.  Returns the server's representation of the componentBuild, and an error, if there is any.
+func (c *componentBuilds) Create(ctx context.Context, componentBuild *v1alpha2.ComponentBuild, opts v1.CreateOptions) (result *v1alpha2.ComponentBuild, err error) {
+	result = &v1alpha2.ComponentBuild{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(componentBuild).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a componentBuild and updates it. Returns the server's representation of the componentBuild, and an error, if there is any.
+func (c *componentBuilds) Update(ctx context.Context, componentBuild *v1alpha2.ComponentBuild, opts v1.UpdateOptions) (result *v1alpha2.ComponentBuild, err error) {
+	result = &v1alpha2.ComponentBuild{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		Name(componentBuild.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(componentBuild).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *componentBuilds) UpdateStatus(ctx context.Context, componentBuild *v1alpha2.ComponentBuild, opts v1.UpdateOptions) (result *v1alpha2.ComponentBuild, err error) {
+	result = &v1alpha2.ComponentBuild{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		Name(componentBuild.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(componentBuild).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the componentBuild and deletes it. Returns an error if one occurs.
+func (c *componentBuilds) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *componentBuilds) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched componentBuild.
+func (c *componentBuilds) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.ComponentBuild, err error) {
+	result = &v1alpha2.ComponentBuild{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("componentbuilds").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"context"
+	"time"
+
+	v1alpha2 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	scheme "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// DeploymentRecordsGetter has a method to return a DeploymentRecordInterface.
+// A group's client should implement this interface.
+type DeploymentRecordsGetter interface {
+	DeploymentRecords(namespace string) DeploymentRecordInterface
+}
+
+// DeploymentRecordInterface has methods to work with DeploymentRecord resources.
+type DeploymentRecordInterface interface {
+	Create(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.CreateOptions) (*v1alpha2.DeploymentRecord, error)
+	Update(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.UpdateOptions) (*v1alpha2.DeploymentRecord, error)
+	UpdateStatus(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.UpdateOptions) (*v1alpha2.DeploymentRecord, error)
+	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
+	DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error
+	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1alpha2.DeploymentRecord, error)
+	List(ctx context.Context, opts v1.ListOptions) (*v1alpha2.DeploymentRecordList, error)
+	Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error)
+	Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.DeploymentRecord, err error)
+	DeploymentRecordExpansion
+}
+
+// deploymentRecords implements DeploymentRecordInterface
+type deploymentRecords struct {
+	client rest.Interface
+	ns     string
+}
+
+// newDeploymentRecords returns a DeploymentRecords
+func newDeploymentRecords(c *ApheleiaV1alpha2Client, namespace string) *deploymentRecords {
+	return &deploymentRecords{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the deploymentRecord, and returns the corresponding deploymentRecord object, and an error if there is any.
+func (c *deploymentRecords) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha2.DeploymentRecord, err error) {
+	result = &v1alpha2.DeploymentRecord{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of DeploymentRecords that match those selectors.
+func (c *deploymentRecords) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha2.DeploymentRecordList, err error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	result = &v1alpha2.DeploymentRecordList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested deploymentRecords.
+func (c *deploymentRecords) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	var timeout time.Duration
+	if opts.TimeoutSeconds != nil {
+		timeout = time.Duration(*opts.TimeoutSeconds) * time.Second
+	}
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Watch(ctx)
+}
+
+// Create takes the representation of a deploymentRecord and creates it.  Returns the server's representation of the deploymentRecord, and an error, if there is any.
+func (c *deploymentRecords) Create(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.CreateOptions) (result *v1alpha2.DeploymentRecord, err error) {
+	result = &v1alpha2.DeploymentRecord{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(deploymentRecord).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Update takes the representation of a deploymentRecord and updates it. Returns the server's representation of the deploymentRecord, and an error, if there is any.
+func (c *deploymentRecords) Update(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.UpdateOptions) (result *v1alpha2.DeploymentRecord, err error) {
+	result = &v1alpha2.DeploymentRecord{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		Name(deploymentRecord.Name).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(deploymentRecord).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *deploymentRecords) UpdateStatus(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.UpdateOptions) (result *v1alpha2.DeploymentRecord, err error) {
+	result = &v1alpha2.DeploymentRecord{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		Name(deploymentRecord.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(deploymentRecord).
+		Do(ctx).
+		Into(result)
+	return
+}
+
+// Delete takes name of the deploymentRecord and deletes it. Returns an error if one occurs.
+func (c *deploymentRecords) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		Name(name).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *deploymentRecords) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	var timeout time.Duration
+	if listOpts.TimeoutSeconds != nil {
+		timeout = time.Duration(*listOpts.TimeoutSeconds) * time.Second
+	}
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		VersionedParams(&listOpts, scheme.ParameterCodec).
+		Timeout(timeout).
+		Body(&opts).
+		Do(ctx).
+		Error()
+}
+
+// Patch applies the patch and returns the patched deploymentRecord.
+func (c *deploymentRecords) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.DeploymentRecord, err error) {
+	result = &v1alpha2.DeploymentRecord{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("deploymentrecords").
+		Name(name).
+		SubResource(subresources...).
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(data).
+		Do(ctx).
+		Into(result)
+	return
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by client-gen. DO NOT EDIT.
+
+package fake
+
+import (
+	"context"
+
+	v1alpha2 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeDeploymentRecords implements DeploymentRecordInterface
+type FakeDeploymentRecords struct {
+	Fake *FakeApheleiaV1alpha2
+	ns   string
+}
+
+var deploymentrecordsResource = schema.GroupVersionResource{Group: "apheleia.io", Version: "v1alpha2", Resource: "deploymentrecords"}
+
+var deploymentrecordsKind = schema.GroupVersionKind{Group: "apheleia.io", Version: "v1alpha2", Kind: "DeploymentRecord"}
+
+// Get takes name of the deploymentRecord, and returns the corresponding deploymentRecord object, and an error if there is any.
+func (c *FakeDeploymentRecords) Get(ctx context.Context, name string, options v1.GetOptions) (result *v1alpha2.DeploymentRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(deploymentrecordsResource, c.ns, name), &v1alpha2.DeploymentRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.DeploymentRecord), err
+}
+
+// List takes label and field selectors, and returns the list of DeploymentRecords that match those selectors.
+func (c *FakeDeploymentRecords) List(ctx context.Context, opts v1.ListOptions) (result *v1alpha2.DeploymentRecordList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(deploymentrecordsResource, deploymentrecordsKind, c.ns, opts), &v1alpha2.DeploymentRecordList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha2.DeploymentRecordList{ListMeta: obj.(*v1alpha2.DeploymentRecordList).ListMeta}
+	for _, item := range obj.(*v1alpha2.DeploymentRecordList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested deploymentRecords.
+func (c *FakeDeploymentRecords) Watch(ctx context.Context, opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(deploymentrecordsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a deploymentRecord and creates it.  Returns the server's representation of the deploymentRecord, and an error, if there is any.
+func (c *FakeDeploymentRecords) Create(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.CreateOptions) (result *v1alpha2.DeploymentRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(deploymentrecordsResource, c.ns, deploymentRecord), &v1alpha2.DeploymentRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.DeploymentRecord), err
+}
+
+// Update takes the representation of a deploymentRecord and updates it. Returns the server's representation of the deploymentRecord, and an error, if there is any.
+func (c *FakeDeploymentRecords) Update(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.UpdateOptions) (result *v1alpha2.DeploymentRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(deploymentrecordsResource, c.ns, deploymentRecord), &v1alpha2.DeploymentRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.DeploymentRecord), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeDeploymentRecords) UpdateStatus(ctx context.Context, deploymentRecord *v1alpha2.DeploymentRecord, opts v1.UpdateOptions) (*v1alpha2.DeploymentRecord, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(deploymentrecordsResource, "status", c.ns, deploymentRecord), &v1alpha2.DeploymentRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.DeploymentRecord), err
+}
+
+// Delete takes name of the deploymentRecord and deletes it. Returns an error if one occurs.
+func (c *FakeDeploymentRecords) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteActionWithOptions(deploymentrecordsResource, c.ns, name, opts), &v1alpha2.DeploymentRecord{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeDeploymentRecords) DeleteCollection(ctx context.Context, opts v1.DeleteOptions, listOpts v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(deploymentrecordsResource, c.ns, listOpts)
+
+	_, err := c.Fake.Invokes(action, &v1alpha2.DeploymentRecordList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched deploymentRecord.
+func (c *FakeDeploymentRecords) Patch(ctx context.Context, name string, pt types.PatchType, data []byte, opts v1.PatchOptions, subresources ...string) (result *v1alpha2.DeploymentRecord, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(deploymentrecordsResource, c.ns, name, pt, data, subresources...), &v1alpha2.DeploymentRecord{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha2.DeploymentRecord), err
+}
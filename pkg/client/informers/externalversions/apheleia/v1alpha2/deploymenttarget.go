@@ -0,0 +1,89 @@
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	"context"
+	time "time"
+
+	apheleiav1alpha2 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	versioned "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned"
+	internalinterfaces "github.com/apheleia-project/apheleia/pkg/client/informers/externalversions/internalinterfaces"
+	v1alpha2 "github.com/apheleia-project/apheleia/pkg/client/listers/apheleia/v1alpha2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+)
+
+// DeploymentTargetInformer provides access to a shared informer and lister for
+// DeploymentTargets.
+type DeploymentTargetInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha2.DeploymentTargetLister
+}
+
+type deploymentTargetInformer struct {
+	factory          internalinterfaces.SharedInformerFactory
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+	namespace        string
+}
+
+// NewDeploymentTargetInformer constructs a new informer for DeploymentTarget type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewDeploymentTargetInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return NewFilteredDeploymentTargetInformer(client, namespace, resyncPeriod, indexers, nil)
+}
+
+// NewFilteredDeploymentTargetInformer constructs a new informer for DeploymentTarget type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewFilteredDeploymentTargetInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers, tweakListOptions internalinterfaces.TweakListOptionsFunc) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ApheleiaV1alpha2().DeploymentTargets(namespace).List(context.TODO(), options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				if tweakListOptions != nil {
+					tweakListOptions(&options)
+				}
+				return client.ApheleiaV1alpha2().DeploymentTargets(namespace).Watch(context.TODO(), options)
+			},
+		},
+		&apheleiav1alpha2.DeploymentTarget{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func (f *deploymentTargetInformer) defaultInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewFilteredDeploymentTargetInformer(client, f.namespace, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc}, f.tweakListOptions)
+}
+
+func (f *deploymentTargetInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&apheleiav1alpha2.DeploymentTarget{}, f.defaultInformer)
+}
+
+func (f *deploymentTargetInformer) Lister() v1alpha2.DeploymentTargetLister {
+	return v1alpha2.NewDeploymentTargetLister(f.Informer().GetIndexer())
+}
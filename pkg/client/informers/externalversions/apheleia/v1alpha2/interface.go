@@ -0,0 +1,58 @@
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by informer-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	internalinterfaces "github.com/apheleia-project/apheleia/pkg/client/informers/externalversions/internalinterfaces"
+)
+
+// Interface provides access to all the informers in this group version.
+type Interface interface {
+	// ComponentBuilds returns a ComponentBuildInformer.
+	ComponentBuilds() ComponentBuildInformer
+	// DeploymentRecords returns a DeploymentRecordInformer.
+	DeploymentRecords() DeploymentRecordInformer
+	// DeploymentTargets returns a DeploymentTargetInformer.
+	DeploymentTargets() DeploymentTargetInformer
+}
+
+type version struct {
+	factory          internalinterfaces.SharedInformerFactory
+	namespace        string
+	tweakListOptions internalinterfaces.TweakListOptionsFunc
+}
+
+// New returns a new Interface.
+func New(f internalinterfaces.SharedInformerFactory, namespace string, tweakListOptions internalinterfaces.TweakListOptionsFunc) Interface {
+	return &version{factory: f, namespace: namespace, tweakListOptions: tweakListOptions}
+}
+
+// ComponentBuilds returns a ComponentBuildInformer.
+func (v *version) ComponentBuilds() ComponentBuildInformer {
+	return &componentBuildInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// DeploymentRecords returns a DeploymentRecordInformer.
+func (v *version) DeploymentRecords() DeploymentRecordInformer {
+	return &deploymentRecordInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// DeploymentTargets returns a DeploymentTargetInformer.
+func (v *version) DeploymentTargets() DeploymentTargetInformer {
+	return &deploymentTargetInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
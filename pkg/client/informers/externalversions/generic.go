@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha1"
+	v1alpha2 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -55,6 +56,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	case v1alpha1.SchemeGroupVersion.WithResource("componentbuilds"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Apheleia().V1alpha1().ComponentBuilds().Informer()}, nil
 
+		// Group=apheleia.io, Version=v1alpha2
+	case v1alpha2.SchemeGroupVersion.WithResource("componentbuilds"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Apheleia().V1alpha2().ComponentBuilds().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("deploymentrecords"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Apheleia().V1alpha2().DeploymentRecords().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("deploymenttargets"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Apheleia().V1alpha2().DeploymentTargets().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
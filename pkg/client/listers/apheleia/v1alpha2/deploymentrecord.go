@@ -0,0 +1,98 @@
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha2
+
+import (
+	v1alpha2 "github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// DeploymentRecordLister helps list DeploymentRecords.
+// All objects returned here must be treated as read-only.
+type DeploymentRecordLister interface {
+	// List lists all DeploymentRecords in the indexer.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha2.DeploymentRecord, err error)
+	// DeploymentRecords returns an object that can list and get DeploymentRecords.
+	DeploymentRecords(namespace string) DeploymentRecordNamespaceLister
+	DeploymentRecordListerExpansion
+}
+
+// deploymentRecordLister implements the DeploymentRecordLister interface.
+type deploymentRecordLister struct {
+	indexer cache.Indexer
+}
+
+// NewDeploymentRecordLister returns a new DeploymentRecordLister.
+func NewDeploymentRecordLister(indexer cache.Indexer) DeploymentRecordLister {
+	return &deploymentRecordLister{indexer: indexer}
+}
+
+// List lists all DeploymentRecords in the indexer.
+func (s *deploymentRecordLister) List(selector labels.Selector) (ret []*v1alpha2.DeploymentRecord, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha2.DeploymentRecord))
+	})
+	return ret, err
+}
+
+// DeploymentRecords returns an object that can list and get DeploymentRecords.
+func (s *deploymentRecordLister) DeploymentRecords(namespace string) DeploymentRecordNamespaceLister {
+	return deploymentRecordNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// DeploymentRecordNamespaceLister helps list and get DeploymentRecords.
+// All objects returned here must be treated as read-only.
+type DeploymentRecordNamespaceLister interface {
+	// List lists all DeploymentRecords in the indexer for a given namespace.
+	// Objects returned here must be treated as read-only.
+	List(selector labels.Selector) (ret []*v1alpha2.DeploymentRecord, err error)
+	// Get retrieves the DeploymentRecord from the indexer for a given namespace and name.
+	// Objects returned here must be treated as read-only.
+	Get(name string) (*v1alpha2.DeploymentRecord, error)
+	DeploymentRecordNamespaceListerExpansion
+}
+
+// deploymentRecordNamespaceLister implements the DeploymentRecordNamespaceLister
+// interface.
+type deploymentRecordNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all DeploymentRecords in the indexer for a given namespace.
+func (s deploymentRecordNamespaceLister) List(selector labels.Selector) (ret []*v1alpha2.DeploymentRecord, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha2.DeploymentRecord))
+	})
+	return ret, err
+}
+
+// Get retrieves the DeploymentRecord from the indexer for a given namespace and name.
+func (s deploymentRecordNamespaceLister) Get(name string) (*v1alpha2.DeploymentRecord, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha2.Resource("deploymentrecord"), name)
+	}
+	return obj.(*v1alpha2.DeploymentRecord), nil
+}
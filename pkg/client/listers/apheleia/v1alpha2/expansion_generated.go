@@ -0,0 +1,42 @@
+/*
+Copyright 2021-2022 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+// Code generated by lister-gen. DO NOT EDIT.
+
+package v1alpha2
+
+// ComponentBuildListerExpansion allows custom methods to be added to
+// ComponentBuildLister.
+type ComponentBuildListerExpansion interface{}
+
+// ComponentBuildNamespaceListerExpansion allows custom methods to be added to
+// ComponentBuildNamespaceLister.
+type ComponentBuildNamespaceListerExpansion interface{}
+
+// DeploymentRecordListerExpansion allows custom methods to be added to
+// DeploymentRecordLister.
+type DeploymentRecordListerExpansion interface{}
+
+// DeploymentRecordNamespaceListerExpansion allows custom methods to be added to
+// DeploymentRecordNamespaceLister.
+type DeploymentRecordNamespaceListerExpansion interface{}
+
+// DeploymentTargetListerExpansion allows custom methods to be added to
+// DeploymentTargetLister.
+type DeploymentTargetListerExpansion interface{}
+
+// DeploymentTargetNamespaceListerExpansion allows custom methods to be added to
+// DeploymentTargetNamespaceLister.
+type DeploymentTargetNamespaceListerExpansion interface{}
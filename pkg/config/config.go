@@ -0,0 +1,230 @@
+// Package config loads the configuration file of the controller. Settings that only affect what a reconcile does are
+// reloaded when the file changes, structural settings such as the number of workers need a restart.
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the version of the configuration file format
+	APIVersion = "apheleia.io/v1alpha1"
+	// Kind is the kind of the configuration file
+	Kind = "ControllerConfig"
+
+	DefaultReconcileTimeout        = 300 * time.Second
+	DefaultMaxConcurrentReconciles = 1
+	DefaultDeployTask              = "apheleia-deploy"
+	DefaultNotifierPipeline        = "component-build-notifier"
+	DefaultNotifierSecret          = "jvm-build-git-secrets"
+	DefaultNotifierWorkspaceSize   = "1Gi"
+
+	// DefaultNotifierHosts are the hosts the token in the notifier secret is sent to if none are configured
+	DefaultNotifierHosts = "github.com,gitlab.com"
+
+	// reloadInterval is how often the file is checked for changes. It is polled rather than watched, as config maps
+	// mounted into the pod are updated by swapping a symlink.
+	reloadInterval = 10 * time.Second
+)
+
+// ControllerConfig holds the settings of the controller. Fields that are left out of the file have their defaults.
+type ControllerConfig struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// ReconcileTimeout limits how long a single reconcile can take
+	ReconcileTimeout metav1.Duration `json:"reconcileTimeout,omitempty"`
+	// MaxConcurrentReconciles is the number of workers of each controller, it is only read at startup
+	MaxConcurrentReconciles int `json:"maxConcurrentReconciles,omitempty"`
+	// DeployTask is the name of the ClusterTask that deploys to CodeArtifact
+	DeployTask string `json:"deployTask,omitempty"`
+	// NotifierPipeline is the name of the Pipeline that comments on pull requests for the tekton notifier
+	NotifierPipeline string `json:"notifierPipeline,omitempty"`
+	// NotifierSecret is the secret with the git credentials used to notify pull requests, when the namespace does not
+	// configure one
+	NotifierSecret string `json:"notifierSecret,omitempty"`
+	// NotifierWorkspaceSize is the size of the volume claimed for the workspace of the notifier pipeline
+	NotifierWorkspaceSize resource.Quantity `json:"notifierWorkspaceSize,omitempty"`
+	// NotifierHosts are the hosts of pull requests and repositories that the token in the notifier secret may be sent
+	// to. Other hosts only get the credentials for them in the git credentials of the secret, unless the namespace
+	// configures the notification URL.
+	NotifierHosts []string `json:"notifierHosts,omitempty"`
+	// ConsoleURL is the URL of the OpenShift console, if it is set the ArtifactBuilds in progress comments link to it
+	ConsoleURL string `json:"consoleURL,omitempty"`
+}
+
+// Defaults returns the configuration that is used without a file
+func Defaults() *ControllerConfig {
+	c := &ControllerConfig{APIVersion: APIVersion, Kind: Kind}
+	c.setDefaults()
+	return c
+}
+
+func (c *ControllerConfig) setDefaults() {
+	if c.ReconcileTimeout.Duration == 0 {
+		c.ReconcileTimeout.Duration = DefaultReconcileTimeout
+	}
+	if c.MaxConcurrentReconciles == 0 {
+		c.MaxConcurrentReconciles = DefaultMaxConcurrentReconciles
+	}
+	if c.DeployTask == "" {
+		c.DeployTask = DefaultDeployTask
+	}
+	if c.NotifierPipeline == "" {
+		c.NotifierPipeline = DefaultNotifierPipeline
+	}
+	if c.NotifierSecret == "" {
+		c.NotifierSecret = DefaultNotifierSecret
+	}
+	if c.NotifierWorkspaceSize.IsZero() {
+		c.NotifierWorkspaceSize = resource.MustParse(DefaultNotifierWorkspaceSize)
+	}
+	if c.NotifierHosts == nil {
+		c.NotifierHosts = strings.Split(DefaultNotifierHosts, ",")
+	}
+}
+
+// Validate returns an error describing every invalid setting
+func (c *ControllerConfig) Validate() error {
+	var problems []string
+	if c.APIVersion != APIVersion || c.Kind != Kind {
+		problems = append(problems, fmt.Sprintf("apiVersion and kind must be %s %s, not %q %q", APIVersion, Kind, c.APIVersion, c.Kind))
+	}
+	if c.ReconcileTimeout.Duration < 0 {
+		problems = append(problems, fmt.Sprintf("reconcileTimeout must be positive, not %s", c.ReconcileTimeout.Duration))
+	}
+	if c.MaxConcurrentReconciles < 0 {
+		problems = append(problems, fmt.Sprintf("maxConcurrentReconciles must be positive, not %d", c.MaxConcurrentReconciles))
+	}
+	for field, name := range map[string]string{"deployTask": c.DeployTask, "notifierPipeline": c.NotifierPipeline, "notifierSecret": c.NotifierSecret} {
+		for _, msg := range validation.IsDNS1123Subdomain(name) {
+			problems = append(problems, fmt.Sprintf("%s %q is not a valid name: %s", field, name, msg))
+		}
+	}
+	if c.NotifierWorkspaceSize.Sign() < 0 {
+		problems = append(problems, fmt.Sprintf("notifierWorkspaceSize must be positive, not %s", c.NotifierWorkspaceSize.String()))
+	}
+	for _, host := range c.NotifierHosts {
+		if u, err := url.Parse("//" + host); err != nil || u.Host != host || host == "" {
+			problems = append(problems, fmt.Sprintf("notifierHosts must only contain host names, not %q", host))
+		}
+	}
+	if c.ConsoleURL != "" {
+		if u, err := url.Parse(c.ConsoleURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			problems = append(problems, fmt.Sprintf("consoleURL %q must be an http or https URL", c.ConsoleURL))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid %s: %v", Kind, problems)
+	}
+	return nil
+}
+
+// Parse reads the configuration from the YAML data, unknown fields are rejected so that typos are noticed
+func Parse(data []byte) (*ControllerConfig, error) {
+	c := &ControllerConfig{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", Kind, err.Error())
+	}
+	c.setDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Store holds the current configuration and reloads it while the manager runs. A nil Store returns the defaults.
+type Store struct {
+	path    string
+	lock    sync.RWMutex
+	current *ControllerConfig
+	data    []byte
+}
+
+// NewStore loads the configuration file, if the path is empty the defaults are used and nothing is reloaded
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path, current: Defaults()}
+	if path == "" {
+		return s, nil
+	}
+	if _, err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get returns the current configuration, which must not be modified
+func (s *Store) Get() *ControllerConfig {
+	if s == nil {
+		return Defaults()
+	}
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.current
+}
+
+// reload reads the file again and returns true if the configuration changed. Structural settings keep the value they
+// were started with.
+func (s *Store) reload() (bool, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return false, err
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.data != nil && bytes.Equal(data, s.data) {
+		return false, nil
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return false, err
+	}
+	if s.data != nil && c.MaxConcurrentReconciles != s.current.MaxConcurrentReconciles {
+		ctrl.Log.WithName("config").Info("maxConcurrentReconciles only changes when the controller is restarted", "current", s.current.MaxConcurrentReconciles, "configured", c.MaxConcurrentReconciles)
+		c.MaxConcurrentReconciles = s.current.MaxConcurrentReconciles
+	}
+	s.current = c
+	s.data = data
+	return true, nil
+}
+
+// Start reloads the file when it changes until the context is done. An invalid file is logged and the previous
+// configuration is kept.
+func (s *Store) Start(ctx context.Context) error {
+	if s.path == "" {
+		return nil
+	}
+	log := ctrl.Log.WithName("config")
+	ticker := time.NewTicker(reloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			changed, err := s.reload()
+			if err != nil {
+				log.Error(err, "Unable to reload the controller configuration, keeping the current one", "path", s.path)
+			} else if changed {
+				log.Info("Reloaded the controller configuration", "path", s.path)
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false, every replica reloads its own configuration
+func (s *Store) NeedLeaderElection() bool {
+	return false
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const header = "apiVersion: apheleia.io/v1alpha1\nkind: ControllerConfig\n"
+
+func writeConfig(g *WithT, path string, data string) {
+	g.Expect(os.WriteFile(path, []byte(data), 0600)).NotTo(HaveOccurred())
+}
+
+func TestParse(t *testing.T) {
+	g := NewGomegaWithT(t)
+	c, err := Parse([]byte(header + "reconcileTimeout: 1m\nmaxConcurrentReconciles: 4\ndeployTask: deploy\nconsoleURL: https://console.example.com\n"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.ReconcileTimeout.Duration).To(Equal(time.Minute))
+	g.Expect(c.MaxConcurrentReconciles).To(Equal(4))
+	g.Expect(c.DeployTask).To(Equal("deploy"))
+	g.Expect(c.ConsoleURL).To(Equal("https://console.example.com"))
+	//settings that are left out have their defaults
+	g.Expect(c.NotifierPipeline).To(Equal(DefaultNotifierPipeline))
+	g.Expect(c.NotifierSecret).To(Equal(DefaultNotifierSecret))
+	g.Expect(c.NotifierWorkspaceSize.String()).To(Equal(DefaultNotifierWorkspaceSize))
+	g.Expect(c.NotifierHosts).To(Equal([]string{"github.com", "gitlab.com"}))
+
+	c, err = Parse([]byte(header))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c).To(Equal(Defaults()))
+}
+
+func TestParseInvalid(t *testing.T) {
+	g := NewGomegaWithT(t)
+	for _, data := range []string{
+		"kind: ControllerConfig\n",
+		"apiVersion: apheleia.io/v2\nkind: ControllerConfig\n",
+		header + "reconcileTimeout: soon\n",
+		header + "reconcileTimeout: -1m\n",
+		header + "maxConcurrentReconciles: -1\n",
+		header + "deployTask: Not_A_Name\n",
+		header + "notifierWorkspaceSize: -1Gi\n",
+		header + "consoleURL: console.example.com\n",
+		header + "notifierHosts: [https://github.example.com]\n",
+		//typos are rejected rather than silently using the default
+		header + "notifierSecrets: git\n",
+	} {
+		_, err := Parse([]byte(data))
+		g.Expect(err).To(HaveOccurred(), data)
+	}
+}
+
+func TestStoreReload(t *testing.T) {
+	g := NewGomegaWithT(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(g, path, header+"maxConcurrentReconciles: 2\n")
+	s, err := NewStore(path)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.Get().MaxConcurrentReconciles).To(Equal(2))
+
+	changed, err := s.reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeFalse())
+
+	//settings are reloaded, except for the number of workers which needs a restart
+	writeConfig(g, path, header+"maxConcurrentReconciles: 8\ndeployTask: deploy\n")
+	changed, err = s.reload()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(changed).To(BeTrue())
+	g.Expect(s.Get().DeployTask).To(Equal("deploy"))
+	g.Expect(s.Get().MaxConcurrentReconciles).To(Equal(2))
+
+	//an invalid file keeps the current configuration
+	writeConfig(g, path, header+"deployTask: Not_A_Name\n")
+	_, err = s.reload()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(s.Get().DeployTask).To(Equal("deploy"))
+}
+
+func TestStoreDefaults(t *testing.T) {
+	g := NewGomegaWithT(t)
+	s, err := NewStore("")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(s.Get()).To(Equal(Defaults()))
+	var unset *Store
+	g.Expect(unset.Get()).To(Equal(Defaults()))
+
+	_, err = NewStore(filepath.Join(t.TempDir(), "missing.yaml"))
+	g.Expect(err).To(HaveOccurred())
+}
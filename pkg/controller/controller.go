@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha1"
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/apheleia-project/apheleia/pkg/config"
 	"github.com/apheleia-project/apheleia/pkg/reconciler/componentbuild"
+	"github.com/apheleia-project/apheleia/pkg/reconciler/deploymenttarget"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
 	"time"
@@ -12,6 +16,7 @@ import (
 	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
 	pipelinev1beta1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -26,7 +31,7 @@ var (
 	controllerLog = ctrl.Log.WithName("controller")
 )
 
-func NewManager(cfg *rest.Config, options ctrl.Options) (ctrl.Manager, error) {
+func NewManager(cfg *rest.Config, options ctrl.Options, store *config.Store) (ctrl.Manager, error) {
 	// do not check tekton in kcp
 	// we have seen in e2e testing that this path can get invoked prior to the TaskRun CRD getting generated,
 	// and controller-runtime does not retry on missing CRDs.
@@ -61,34 +66,59 @@ func NewManager(cfg *rest.Config, options ctrl.Options) (ctrl.Manager, error) {
 	if err := v1alpha1.AddToScheme(options.Scheme); err != nil {
 		return nil, err
 	}
+	if err := v1alpha2.AddToScheme(options.Scheme); err != nil {
+		return nil, err
+	}
 
-	var mgr ctrl.Manager
-	var err error
-
-	//if we are running this locally on the same cluster as the ckcp we want to ignore any synced pipeline runs
-	deployTask := labels.NewSelector()
-	requirement, lerr := labels.NewRequirement(componentbuild.DeployTaskLabel, selection.Exists, []string{})
-	if lerr != nil {
-		return nil, lerr
+	//only the runs created by the operator are cached, so the build PipelineRuns and TaskRuns of the JVM build service
+	//and anything else running on the cluster do not reach the work queues
+	deployTask, err := labelExists(componentbuild.DeployTaskLabel)
+	if err != nil {
+		return nil, err
+	}
+	notifyPipeline, err := labelExists(componentbuild.NotifyPipelineLabel)
+	if err != nil {
+		return nil, err
 	}
-	deployTask.Add(*requirement)
 	options.NewCache = cache.BuilderWithOptions(cache.Options{
 		SelectorsByObject: cache.SelectorsByObject{
-			&v1alpha1.ComponentBuild{}:     {},
+			&v1alpha2.ComponentBuild{}:     {},
+			&v1alpha2.DeploymentTarget{}:   {},
+			&v1alpha2.DeploymentRecord{}:   {},
 			&jvmbs.ArtifactBuild{}:         {},
-			&pipelinev1beta1.PipelineRun{}: {},
+			&jvmbs.DependencyBuild{}:       {},
+			&jvmbs.RebuiltArtifact{}:       {},
+			&pipelinev1beta1.PipelineRun{}: {Label: notifyPipeline},
 			&pipelinev1beta1.TaskRun{}:     {Label: deployTask},
+			//the only config maps that are read are the apheleia-config of each namespace
+			&corev1.ConfigMap{}: {Field: fields.OneTermEqualSelector("metadata.name", componentbuild.ApheleiaConfig)},
 		}})
 
-	mgr, err = ctrl.NewManager(cfg, options)
+	mgr, err := ctrl.NewManager(cfg, options)
 
 	if err != nil {
 		return nil, err
 	}
 
-	if err := componentbuild.SetupNewReconcilerWithManager(mgr); err != nil {
+	//the configuration file is reloaded while the manager runs
+	if err := mgr.Add(store); err != nil {
+		return nil, err
+	}
+	if err := componentbuild.SetupNewReconcilerWithManager(mgr, store); err != nil {
+		return nil, err
+	}
+	if err := deploymenttarget.SetupNewReconcilerWithManager(mgr, store); err != nil {
 		return nil, err
 	}
 
 	return mgr, nil
 }
+
+// labelExists returns a selector that matches the objects that have the label
+func labelExists(label string) (labels.Selector, error) {
+	requirement, err := labels.NewRequirement(label, selection.Exists, []string{})
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(*requirement), nil
+}
@@ -0,0 +1,147 @@
+package componentbuild
+
+import (
+	"context"
+	"strings"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ComponentBuildFinalizer lets the operator release the ArtifactBuilds of a ComponentBuild before it is deleted
+	ComponentBuildFinalizer = "apheleia.io/componentbuild"
+	// ReferenceAnnotationPrefix is followed by the UID of a ComponentBuild on the ArtifactBuilds it references, the
+	// value is the name of the ComponentBuild. Owner references are not used, as the garbage collector would delete
+	// the ArtifactBuilds when a ComponentBuild is deleted without its finalizer.
+	ReferenceAnnotationPrefix = "componentbuild.apheleia.io/"
+)
+
+// referenceArtifactBuild returns the ArtifactBuild for the GAV, creating it if needed, and records that the
+// ComponentBuild references it with an annotation. ComponentBuilds that share a GAV may race to create the
+// ArtifactBuild, so one that already exists is used as is.
+func (r *ReconcileArtifactBuild) referenceArtifactBuild(ctx context.Context, cb *v1alpha2.ComponentBuild, gav string) (*jvmbs.ArtifactBuild, error) {
+	abr := jvmbs.ArtifactBuild{}
+	key := types.NamespacedName{Namespace: cb.Namespace, Name: artifactbuild.CreateABRName(gav)}
+	err := r.client.Get(ctx, key, &abr)
+	if errors.IsNotFound(err) {
+		abr = jvmbs.ArtifactBuild{}
+		abr.Spec = jvmbs.ArtifactBuildSpec{GAV: gav}
+		abr.Name = key.Name
+		abr.Namespace = key.Namespace
+		abr.Annotations = map[string]string{referenceAnnotation(cb): cb.Name}
+		err = r.client.Create(ctx, &abr)
+		if err == nil {
+			return &abr, nil
+		}
+		if !errors.IsAlreadyExists(err) {
+			return nil, err
+		}
+		err = r.client.Get(ctx, key, &abr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if referencedBy(&abr, cb) {
+		return &abr, nil
+	}
+	if abr.Annotations == nil {
+		abr.Annotations = map[string]string{}
+	}
+	abr.Annotations[referenceAnnotation(cb)] = cb.Name
+	return &abr, r.client.Update(ctx, &abr)
+}
+
+// referenceAnnotation returns the annotation that records a reference from the ComponentBuild
+func referenceAnnotation(cb *v1alpha2.ComponentBuild) string {
+	return ReferenceAnnotationPrefix + string(cb.UID)
+}
+
+// referencedBy returns true if the ArtifactBuild is annotated as referenced by the ComponentBuild
+func referencedBy(abr *jvmbs.ArtifactBuild, cb *v1alpha2.ComponentBuild) bool {
+	_, ok := abr.Annotations[referenceAnnotation(cb)]
+	return ok
+}
+
+// referenceCount returns the number of ComponentBuilds that reference the ArtifactBuild
+func referenceCount(abr *jvmbs.ArtifactBuild) int {
+	count := 0
+	for key := range abr.Annotations {
+		if strings.HasPrefix(key, ReferenceAnnotationPrefix) {
+			count++
+		}
+	}
+	return count
+}
+
+// releaseArtifactBuild removes the reference of the ComponentBuild from the named ArtifactBuild. If the namespace
+// opted in to deleting unreferenced ArtifactBuilds and nothing else references or owns it, the ArtifactBuild is
+// deleted.
+func (r *ReconcileArtifactBuild) releaseArtifactBuild(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, name string, retention string) error {
+	abr := jvmbs.ArtifactBuild{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: cb.Namespace, Name: name}, &abr)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if !referencedBy(&abr, cb) {
+		return nil
+	}
+	delete(abr.Annotations, referenceAnnotation(cb))
+	if referenceCount(&abr) == 0 && len(abr.OwnerReferences) == 0 && retention == v1alpha2.ArtifactRetentionDelete {
+		log.Info("Deleting ArtifactBuild that is no longer referenced by any ComponentBuild", "name", abr.Name)
+		return client.IgnoreNotFound(r.client.Delete(ctx, &abr, client.Preconditions{ResourceVersion: &abr.ResourceVersion}))
+	}
+	return r.client.Update(ctx, &abr)
+}
+
+// handleComponentBuildDeleted releases the ArtifactBuilds of a ComponentBuild that is being deleted, and then removes
+// the finalizer so the deletion can complete
+func (r *ReconcileArtifactBuild) handleComponentBuildDeleted(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild) (reconcile.Result, error) {
+	if !controllerutil.ContainsFinalizer(cb, ComponentBuildFinalizer) {
+		return reconcile.Result{}, nil
+	}
+	log.Info("Releasing ArtifactBuilds of deleted ComponentBuild", "name", cb.Name)
+	policy, err := r.retentionPolicy(ctx, log, cb.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	for _, name := range referencedArtifactBuilds(cb) {
+		if err := r.releaseArtifactBuild(ctx, log, cb, name, policy.artifacts); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	original := cb.DeepCopy()
+	controllerutil.RemoveFinalizer(cb, ComponentBuildFinalizer)
+	return reconcile.Result{}, r.patchFinalizers(ctx, cb, original)
+}
+
+// referencedArtifactBuilds returns the names of the ArtifactBuilds for the artifacts in the spec, and those in the
+// status in case the spec has changed since it was last reconciled
+func referencedArtifactBuilds(cb *v1alpha2.ComponentBuild) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, a := range cb.Spec.Artifacts {
+		name := artifactbuild.CreateABRName(a.GAV())
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, state := range cb.Status.ArtifactState {
+		if !seen[state.ArtifactBuild] {
+			seen[state.ArtifactBuild] = true
+			names = append(names, state.ArtifactBuild)
+		}
+	}
+	return names
+}
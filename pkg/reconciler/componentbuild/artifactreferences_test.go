@@ -0,0 +1,114 @@
+package componentbuild
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// createReferencingComponentBuilds creates two ComponentBuilds that share the default artifact and reconciles them
+func createReferencingComponentBuilds(g *WithT, client runtimeclient.Client, reconciler *ReconcileArtifactBuild) (*v1alpha2.ComponentBuild, *v1alpha2.ComponentBuild) {
+	ctx := context.TODO()
+	first := defaultComponentBuild()
+	first.UID = "first-uid"
+	second := defaultComponentBuild()
+	second.Name = "other"
+	second.UID = "other-uid"
+	second.Spec.Artifacts = append(second.Spec.Artifacts, v1alpha2.ParseGAV("com.test:other:1.0"))
+	for _, cb := range []*v1alpha2.ComponentBuild{&first, &second} {
+		g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: cb.Name}})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cb.Name}, cb)).NotTo(HaveOccurred())
+		g.Expect(controllerutil.ContainsFinalizer(cb, ComponentBuildFinalizer)).To(BeTrue())
+	}
+	return &first, &second
+}
+
+func TestArtifactBuildReferences(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	first, second := createReferencingComponentBuilds(g, client, reconciler)
+
+	abr := jbs.ArtifactBuild{}
+	abrName := types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(artifact)}
+	g.Expect(client.Get(ctx, abrName, &abr)).NotTo(HaveOccurred())
+	g.Expect(referencedBy(&abr, first)).To(BeTrue())
+	g.Expect(referencedBy(&abr, second)).To(BeTrue())
+	g.Expect(abr.Annotations[ReferenceAnnotationPrefix+"first-uid"]).To(Equal(first.Name))
+	//the garbage collector must not delete the ArtifactBuild if a ComponentBuild is deleted without its finalizer
+	g.Expect(abr.OwnerReferences).To(BeEmpty())
+
+	//reconciling again does not add another reference
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, abrName, &abr)).NotTo(HaveOccurred())
+	g.Expect(referenceCount(&abr)).To(Equal(2))
+
+	//by default unreferenced ArtifactBuilds are kept
+	g.Expect(client.Delete(ctx, second)).NotTo(HaveOccurred())
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: second.Name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(errors.IsNotFound(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: second.Name}, second))).To(BeTrue())
+	g.Expect(client.Get(ctx, abrName, &abr)).NotTo(HaveOccurred())
+	g.Expect(referenceCount(&abr)).To(Equal(1))
+	g.Expect(referencedBy(&abr, first)).To(BeTrue())
+	other := jbs.ArtifactBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName("com.test:other:1.0")}, &other)).NotTo(HaveOccurred())
+	g.Expect(referenceCount(&other)).To(Equal(0))
+}
+
+func TestArtifactBuildRetentionDelete(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retention = &v1alpha2.RetentionSettings{Artifacts: v1alpha2.ArtifactRetentionDelete}
+	})
+	first, second := createReferencingComponentBuilds(g, client, reconciler)
+
+	//the shared ArtifactBuild is only deleted once the last ComponentBuild referencing it is gone
+	abrName := types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(artifact)}
+	otherName := types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName("com.test:other:1.0")}
+	g.Expect(client.Delete(ctx, second)).NotTo(HaveOccurred())
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: second.Name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, abrName, &jbs.ArtifactBuild{})).NotTo(HaveOccurred())
+	g.Expect(errors.IsNotFound(client.Get(ctx, otherName, &jbs.ArtifactBuild{}))).To(BeTrue())
+
+	//removing an artifact from the spec releases it as well
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: first.Name}, first)).NotTo(HaveOccurred())
+	first.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV("com.test:other:1.0")}
+	g.Expect(client.Update(ctx, first)).NotTo(HaveOccurred())
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: first.Name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(errors.IsNotFound(client.Get(ctx, abrName, &jbs.ArtifactBuild{}))).To(BeTrue())
+	g.Expect(client.Get(ctx, otherName, &jbs.ArtifactBuild{})).NotTo(HaveOccurred())
+}
+
+func TestExistingArtifactBuildReferenced(t *testing.T) {
+	g := NewGomegaWithT(t)
+	//ArtifactBuilds created before references were tracked are referenced rather than recreated
+	existing := jbs.ArtifactBuild{}
+	existing.Namespace = namespace
+	existing.Name = artifactbuild.CreateABRName(artifact)
+	existing.Spec.GAV = artifact
+	client, reconciler := setupClientAndReconciler(&existing)
+	cb := defaultComponentBuild()
+	cb.UID = "test-uid"
+	abr, err := reconciler.referenceArtifactBuild(context.TODO(), &cb, artifact)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(referencedBy(abr, &cb)).To(BeTrue())
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: existing.Name}, &existing)).NotTo(HaveOccurred())
+	g.Expect(referencedBy(&existing, &cb)).To(BeTrue())
+}
@@ -0,0 +1,147 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	EventComponentBuildCreated   = "io.apheleia.componentbuild.created"
+	EventComponentBuildCompleted = "io.apheleia.componentbuild.completed"
+	EventComponentBuildFailed    = "io.apheleia.componentbuild.failed"
+	EventArtifactBuilt           = "io.apheleia.artifact.built"
+	EventArtifactFailed          = "io.apheleia.artifact.failed"
+	EventArtifactDeployed        = "io.apheleia.artifact.deployed"
+	EventArtifactDeployFailed    = "io.apheleia.artifact.deploy.failed"
+
+	// maxPendingEvents limits how many undelivered events are kept in the status while the sink is unavailable
+	maxPendingEvents = 100
+)
+
+// cloudEventData is the JSON payload of the events
+type cloudEventData struct {
+	Name          string `json:"name"`
+	Namespace     string `json:"namespace"`
+	SCMURL        string `json:"scmURL,omitempty"`
+	Tag           string `json:"tag,omitempty"`
+	PRURL         string `json:"prURL,omitempty"`
+	GAV           string `json:"gav,omitempty"`
+	ArtifactBuild string `json:"artifactBuild,omitempty"`
+}
+
+// emitEvents queues an event for each transition between the previous and the current status, and then delivers the
+// queued events in order. Events that cannot be delivered stay queued in the status, so they are retried with a
+// backoff even if the operator is restarted. If the status is not updated the transitions are found again by the
+// next reconcile, so every event is delivered at least once. It returns how long until the next retry, or zero if
+// nothing is queued.
+func (r *ReconcileArtifactBuild) emitEvents(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, previous *v1alpha2.ComponentBuildStatus) (time.Duration, error) {
+	settings, err := r.namespaceSettings(ctx, cb.Namespace)
+	if err != nil {
+		return 0, err
+	}
+	sink := settings.CloudEventsSink
+	if sink == "" {
+		cb.Status.PendingEvents = nil
+		return 0, nil
+	}
+	queueEvents(cb, previous)
+	if dropped := len(cb.Status.PendingEvents) - maxPendingEvents; dropped > 0 {
+		log.Info("Dropping undelivered CloudEvents", "name", cb.Name, "sink", sink, "dropped", dropped)
+		cb.Status.PendingEvents = cb.Status.PendingEvents[dropped:]
+	}
+	for len(cb.Status.PendingEvents) > 0 {
+		event := &cb.Status.PendingEvents[0]
+		if event.LastAttemptTime != nil {
+			if wait := time.Until(event.LastAttemptTime.Add(backoff(event.Attempts))); wait > 0 {
+				return wait, nil
+			}
+		}
+		err := sendCloudEvent(ctx, notifierClient, sink, cb, event)
+		if err != nil {
+			attempted := metav1.Now()
+			event.Attempts++
+			event.LastFailure = err.Error()
+			event.LastAttemptTime = &attempted
+			log.Error(err, "Error delivering CloudEvent", "name", cb.Name, "sink", sink, "id", event.ID, "type", event.Type, "attempts", event.Attempts)
+			return backoff(event.Attempts), nil
+		}
+		cb.Status.PendingEvents = cb.Status.PendingEvents[1:]
+	}
+	cb.Status.PendingEvents = nil
+	return 0, nil
+}
+
+// queueEvents adds an event to the status for each transition between the previous and the current status
+func queueEvents(cb *v1alpha2.ComponentBuild, previous *v1alpha2.ComponentBuildStatus) {
+	now := metav1.Now()
+	for _, t := range transitions(previous, &cb.Status) {
+		cb.Status.EventSequence++
+		cb.Status.PendingEvents = append(cb.Status.PendingEvents, v1alpha2.CloudEvent{
+			ID:   fmt.Sprintf("%s-%d", cb.UID, cb.Status.EventSequence),
+			Type: t.Type,
+			GAV:  t.GAV,
+			Time: now,
+		})
+	}
+}
+
+// transitions returns the events for the changes between the previous and the current status
+func transitions(previous *v1alpha2.ComponentBuildStatus, current *v1alpha2.ComponentBuildStatus) []v1alpha2.CloudEvent {
+	var events []v1alpha2.CloudEvent
+	if previous.State == "" && current.State != "" {
+		events = append(events, v1alpha2.CloudEvent{Type: EventComponentBuildCreated})
+	}
+	for _, state := range current.ArtifactState {
+		before := previous.GetArtifactState(state.GAV)
+		if before == nil {
+			before = &v1alpha2.ArtifactState{}
+		}
+		if state.Built && !before.Built {
+			events = append(events, v1alpha2.CloudEvent{Type: EventArtifactBuilt, GAV: state.GAV})
+		}
+		if state.Failed && !before.Failed {
+			events = append(events, v1alpha2.CloudEvent{Type: EventArtifactFailed, GAV: state.GAV})
+		}
+		if state.Done() && !before.Done() {
+			events = append(events, v1alpha2.CloudEvent{Type: EventArtifactDeployed, GAV: state.GAV})
+		}
+		if state.DeployFailed && !before.DeployFailed {
+			events = append(events, v1alpha2.CloudEvent{Type: EventArtifactDeployFailed, GAV: state.GAV})
+		}
+	}
+	if current.State != previous.State {
+		switch current.State {
+		case v1alpha2.ComponentBuildStateComplete:
+			events = append(events, v1alpha2.CloudEvent{Type: EventComponentBuildCompleted})
+		case v1alpha2.ComponentBuildStateFailed:
+			events = append(events, v1alpha2.CloudEvent{Type: EventComponentBuildFailed})
+		}
+	}
+	return events
+}
+
+// sendCloudEvent posts the event to the sink in the binary content mode of the CloudEvents HTTP binding
+func sendCloudEvent(ctx context.Context, client *http.Client, sink string, cb *v1alpha2.ComponentBuild, event *v1alpha2.CloudEvent) error {
+	data := cloudEventData{Name: cb.Name, Namespace: cb.Namespace, SCMURL: cb.Spec.SCMURL, Tag: cb.Spec.Tag, PRURL: cb.Spec.PRURL, GAV: event.GAV}
+	subject := cb.Name
+	if event.GAV != "" {
+		subject = event.GAV
+		if state := cb.Status.GetArtifactState(event.GAV); state != nil {
+			data.ArtifactBuild = state.ArtifactBuild
+		}
+	}
+	header := http.Header{}
+	header.Set("ce-specversion", "1.0")
+	header.Set("ce-id", event.ID)
+	header.Set("ce-type", event.Type)
+	header.Set("ce-source", fmt.Sprintf("/apis/%s/namespaces/%s/componentbuilds/%s", v1alpha2.SchemeGroupVersion.String(), cb.Namespace, cb.Name))
+	header.Set("ce-subject", subject)
+	header.Set("ce-time", event.Time.UTC().Format(time.RFC3339))
+	return postJSON(ctx, client, sink, header, data)
+}
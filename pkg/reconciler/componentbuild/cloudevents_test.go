@@ -0,0 +1,110 @@
+package componentbuild
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// eventSink records the types of the CloudEvents it receives, failing them while status is set
+type eventSink struct {
+	notificationServer
+	types []string
+}
+
+func (s *eventSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.notificationServer.ServeHTTP(w, r)
+	if s.status == 0 {
+		s.types = append(s.types, r.Header.Get("ce-type"))
+	}
+}
+
+func TestTransitions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	previous := &v1alpha2.ComponentBuildStatus{}
+	current := &v1alpha2.ComponentBuildStatus{State: v1alpha2.ComponentBuildStateInProgress, ArtifactState: []v1alpha2.ArtifactState{{GAV: artifact}}}
+	g.Expect(transitions(previous, current)).To(Equal([]v1alpha2.CloudEvent{{Type: EventComponentBuildCreated}}))
+
+	previous = current.DeepCopy()
+	current.ArtifactState[0].Built = true
+	g.Expect(transitions(previous, current)).To(Equal([]v1alpha2.CloudEvent{{Type: EventArtifactBuilt, GAV: artifact}}))
+
+	//nothing changed, so nothing is emitted
+	g.Expect(transitions(current, current)).To(BeEmpty())
+
+	previous = current.DeepCopy()
+	current.ArtifactState[0].DeployFailed = true
+	current.State = v1alpha2.ComponentBuildStateFailed
+	g.Expect(transitions(previous, current)).To(Equal([]v1alpha2.CloudEvent{{Type: EventArtifactDeployFailed, GAV: artifact}, {Type: EventComponentBuildFailed}}))
+}
+
+func TestEmitEvents(t *testing.T) {
+	g := NewGomegaWithT(t)
+	sink := &eventSink{}
+	server := httptest.NewServer(sink)
+	defer server.Close()
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.CloudEventsSink = server.URL
+	})
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sink.types).To(Equal([]string{EventComponentBuildCreated}))
+	g.Expect(sink.header.Get("ce-specversion")).To(Equal("1.0"))
+	g.Expect(sink.header.Get("ce-source")).To(Equal("/apis/apheleia.io/v1alpha2/namespaces/default/componentbuilds/test"))
+	g.Expect(sink.header.Get("ce-subject")).To(Equal(name))
+	g.Expect(sink.body).To(HaveKeyWithValue("scmURL", "https://test.com/test.git"))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.PendingEvents).To(BeEmpty())
+	g.Expect(cb.Status.EventSequence).To(Equal(int64(1)))
+
+	//while the sink is down the events are kept in order, and retried with a backoff
+	sink.status = http.StatusServiceUnavailable
+	abr := jbs.ArtifactBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(artifact)}, &abr)).NotTo(HaveOccurred())
+	abr.Status.State = jbs.ArtifactBuildStateFailed
+	g.Expect(client.Status().Update(ctx, &abr)).NotTo(HaveOccurred())
+	result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(deployBackoffBase))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateFailed))
+	g.Expect(cb.Status.PendingEvents).To(HaveLen(2))
+	g.Expect(cb.Status.PendingEvents[0].Type).To(Equal(EventArtifactFailed))
+	g.Expect(cb.Status.PendingEvents[0].Attempts).To(Equal(1))
+	g.Expect(cb.Status.PendingEvents[0].LastFailure).NotTo(BeEmpty())
+	g.Expect(cb.Status.PendingEvents[1].Type).To(Equal(EventComponentBuildFailed))
+
+	//no delivery is attempted before the backoff has passed
+	requests := sink.requests
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sink.requests).To(Equal(requests))
+
+	//once it has the events are delivered in order
+	sink.status = 0
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	attempted := metav1.NewTime(time.Now().Add(-time.Hour))
+	cb.Status.PendingEvents[0].LastAttemptTime = &attempted
+	g.Expect(client.Status().Update(ctx, &cb)).NotTo(HaveOccurred())
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sink.types).To(Equal([]string{EventComponentBuildCreated, EventArtifactFailed, EventComponentBuildFailed}))
+	g.Expect(sink.header.Get("ce-id")).To(HaveSuffix("-3"))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.PendingEvents).To(BeEmpty())
+}
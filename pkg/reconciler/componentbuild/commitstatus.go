@@ -0,0 +1,147 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+)
+
+const (
+	// CommitStatusContext identifies the statuses set by the operator among the other checks on a commit
+	CommitStatusContext = "apheleia"
+
+	CommitStatusPending = "pending"
+	CommitStatusSuccess = "success"
+	CommitStatusFailure = "failure"
+)
+
+var commitSHA = regexp.MustCompile("^[0-9a-f]{40}$")
+
+// CommitStatusReporter is implemented by notifiers that can set a status on the commit a ComponentBuild was
+// created for
+type CommitStatusReporter interface {
+	// ResolveCommit returns the SHA of the commit the tag of the ComponentBuild points to
+	ResolveCommit(ctx context.Context, cb *v1alpha2.ComponentBuild) (string, error)
+	// SetCommitStatus sets the status of the commit in the repository of the ComponentBuild
+	SetCommitStatus(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, sha string, state string, description string) error
+}
+
+// reportCommitStatus sets the status of the commit that was built if it has changed since it was last set. Nothing is
+// reported for repositories that are not on GitHub or GitLab, or that the notifier secret has no token for.
+func (r *ReconcileArtifactBuild) reportCommitStatus(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild) error {
+	if cb.Spec.SCMURL == "" || cb.Spec.Tag == "" {
+		return nil
+	}
+	state, description := commitStatus(cb)
+	previous := cb.Status.CommitStatus
+	if previous != nil && previous.State == state && previous.Description == description {
+		return nil
+	}
+	reporter, err := r.newCommitStatusReporter(ctx, cb)
+	if err != nil || reporter == nil {
+		return err
+	}
+	sha := ""
+	if previous != nil {
+		sha = previous.SHA
+	}
+	if sha == "" {
+		sha, err = reporter.ResolveCommit(ctx, cb)
+		if err != nil {
+			return err
+		}
+	}
+	err = reporter.SetCommitStatus(ctx, log, cb, sha, state, description)
+	if err != nil {
+		return err
+	}
+	cb.Status.CommitStatus = &v1alpha2.CommitStatus{SHA: sha, State: state, Description: description}
+	return nil
+}
+
+// commitStatus returns the commit status state and description for the ComponentBuild
+func commitStatus(cb *v1alpha2.ComponentBuild) (string, string) {
+	built := 0
+	failed := 0
+	for _, v := range cb.Status.ArtifactState {
+		if v.Built {
+			built++
+		}
+		if v.Failed || v.DeployFailed {
+			failed++
+		}
+	}
+	description := fmt.Sprintf("%d/%d dependencies rebuilt", built, len(cb.Status.ArtifactState))
+	switch cb.Status.State {
+	case v1alpha2.ComponentBuildStateComplete:
+		return CommitStatusSuccess, description
+	case v1alpha2.ComponentBuildStateFailed:
+		return CommitStatusFailure, fmt.Sprintf("%s, %d failed", description, failed)
+	}
+	return CommitStatusPending, description
+}
+
+// newCommitStatusReporter returns the reporter for the repository of the ComponentBuild, or nil if there is none.
+// The provider is the configured notifier if that is GitHub or GitLab, otherwise it is detected from the repository.
+func (r *ReconcileArtifactBuild) newCommitStatusReporter(ctx context.Context, cb *v1alpha2.ComponentBuild) (CommitStatusReporter, error) {
+	settings, err := r.namespaceSettings(ctx, cb.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	notification := settings.GetNotification()
+	repository, err := url.Parse(cb.Spec.SCMURL)
+	if err != nil {
+		return nil, err
+	}
+	provider := notification.Provider
+	if provider != v1alpha2.NotifierGitHub && provider != v1alpha2.NotifierGitLab {
+		if provider != "" {
+			//the URL belongs to the webhook or the pipeline, not to the git server
+			notification.URL = ""
+		}
+		provider = repositoryProvider(cb, repository)
+		if provider == "" {
+			return nil, nil
+		}
+	}
+	_, token, err := r.gitToken(ctx, cb.Namespace, notification, repository.Host)
+	if err != nil || token == "" {
+		return nil, err
+	}
+	return newGitNotifier(provider, notification, repository, token).(CommitStatusReporter), nil
+}
+
+// repositoryProvider detects the provider from the PR URL if that is on the same server as the repository, or from
+// the host of the repository if it is github.com or gitlab.com. It is empty if the provider is not known.
+func repositoryProvider(cb *v1alpha2.ComponentBuild, repository *url.URL) string {
+	if pr, err := url.Parse(cb.Spec.PRURL); err == nil && strings.EqualFold(pr.Host, repository.Host) {
+		if provider := detectNotifier(cb.Spec.PRURL); provider != v1alpha2.NotifierTekton {
+			return provider
+		}
+	}
+	switch strings.ToLower(repository.Host) {
+	case "github.com", "www.github.com":
+		return v1alpha2.NotifierGitHub
+	case "gitlab.com":
+		return v1alpha2.NotifierGitLab
+	}
+	return ""
+}
+
+// repositoryPath returns the path of the repository on its server, without the .git suffix
+func repositoryPath(scmURL string) (string, error) {
+	repository, err := url.Parse(scmURL)
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSuffix(strings.Trim(repository.Path, "/"), ".git")
+	if path == "" {
+		return "", fmt.Errorf("%s is not a repository URL", scmURL)
+	}
+	return path, nil
+}
@@ -0,0 +1,185 @@
+package componentbuild
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var testSHA = strings.Repeat("a1", 20)
+
+func TestCommitStatusDescription(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cb := defaultComponentBuild()
+	cb.Status.State = v1alpha2.ComponentBuildStateInProgress
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, Built: true}, {GAV: "com.test:other:1.0"}, {GAV: "com.test:failed:1.0", Failed: true}}
+	state, description := commitStatus(&cb)
+	g.Expect(state).To(Equal(CommitStatusPending))
+	g.Expect(description).To(Equal("1/3 dependencies rebuilt"))
+	cb.Status.State = v1alpha2.ComponentBuildStateFailed
+	state, description = commitStatus(&cb)
+	g.Expect(state).To(Equal(CommitStatusFailure))
+	g.Expect(description).To(Equal("1/3 dependencies rebuilt, 1 failed"))
+	cb.Status.State = v1alpha2.ComponentBuildStateComplete
+	cb.Status.ArtifactState = cb.Status.ArtifactState[:1]
+	state, description = commitStatus(&cb)
+	g.Expect(state).To(Equal(CommitStatusSuccess))
+	g.Expect(description).To(Equal("1/1 dependencies rebuilt"))
+}
+
+func TestGitHubCommitStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	api := &notificationServer{response: testSHA}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	notifier := &gitHubNotifier{client: server.Client(), api: server.URL, token: "secret"}
+	cb := defaultComponentBuild()
+	cb.Spec.SCMURL = "https://github.com/test/repo.git"
+	cb.Spec.Tag = "v1.0"
+	sha, err := notifier.ResolveCommit(context.TODO(), &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sha).To(Equal(testSHA))
+	g.Expect(api.method).To(Equal(http.MethodGet))
+	g.Expect(api.path).To(Equal("/repos/test/repo/commits/v1.0"))
+
+	g.Expect(notifier.SetCommitStatus(context.TODO(), ctrl.Log, &cb, sha, CommitStatusPending, "0/1 dependencies rebuilt")).To(Succeed())
+	g.Expect(api.method).To(Equal(http.MethodPost))
+	g.Expect(api.path).To(Equal("/repos/test/repo/statuses/" + testSHA))
+	g.Expect(api.header.Get("Authorization")).To(Equal("Bearer secret"))
+	g.Expect(api.body).To(HaveKeyWithValue("state", CommitStatusPending))
+	g.Expect(api.body).To(HaveKeyWithValue("description", "0/1 dependencies rebuilt"))
+	g.Expect(api.body).To(HaveKeyWithValue("context", CommitStatusContext))
+
+	//commit SHAs do not need to be resolved
+	cb.Spec.Tag = testSHA
+	api.requests = 0
+	sha, err = notifier.ResolveCommit(context.TODO(), &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sha).To(Equal(testSHA))
+	g.Expect(api.requests).To(BeZero())
+}
+
+func TestGitLabCommitStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	api := &notificationServer{response: `{"id":"` + testSHA + `"}`}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	notifier := &gitLabNotifier{client: server.Client(), api: server.URL + "/api/v4", token: "secret"}
+	cb := defaultComponentBuild()
+	cb.Spec.SCMURL = "https://gitlab.com/group/repo"
+	cb.Spec.Tag = "v1.0"
+	sha, err := notifier.ResolveCommit(context.TODO(), &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(sha).To(Equal(testSHA))
+	g.Expect(api.path).To(Equal("/api/v4/projects/group%2Frepo/repository/commits/v1.0"))
+
+	g.Expect(notifier.SetCommitStatus(context.TODO(), ctrl.Log, &cb, sha, CommitStatusFailure, "0/1 dependencies rebuilt, 1 failed")).To(Succeed())
+	g.Expect(api.path).To(Equal("/api/v4/projects/group%2Frepo/statuses/" + testSHA))
+	g.Expect(api.header.Get("PRIVATE-TOKEN")).To(Equal("secret"))
+	g.Expect(api.body).To(HaveKeyWithValue("state", "failed"))
+	g.Expect(api.body).To(HaveKeyWithValue("name", CommitStatusContext))
+
+	//GitLab rejects setting the state the commit already has
+	api.status = http.StatusBadRequest
+	api.response = `{"message":"Cannot transition status via :run from :running"}`
+	g.Expect(notifier.SetCommitStatus(context.TODO(), ctrl.Log, &cb, sha, CommitStatusPending, "1/2 dependencies rebuilt")).To(Succeed())
+}
+
+func TestReportCommitStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	api := &notificationServer{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	secret := v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultNotifierSecret, Namespace: namespace}, Data: map[string][]byte{NotifierTokenKey: []byte("secret")}}
+	client, reconciler := setupClientAndReconciler(&secret)
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Notification = &v1alpha2.NotificationSettings{URL: server.URL}
+	})
+	cb := defaultComponentBuild()
+	cb.Spec.SCMURL = "https://github.com/test/test.git"
+	cb.Spec.Tag = testSHA
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(api.requests).To(Equal(1))
+	g.Expect(api.path).To(Equal("/repos/test/test/statuses/" + testSHA))
+	g.Expect(api.body).To(HaveKeyWithValue("state", CommitStatusPending))
+	g.Expect(api.body).To(HaveKeyWithValue("description", "0/1 dependencies rebuilt"))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.CommitStatus).To(Equal(&v1alpha2.CommitStatus{SHA: testSHA, State: CommitStatusPending, Description: "0/1 dependencies rebuilt"}))
+
+	//an unchanged status is not set again
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(api.requests).To(Equal(1))
+
+	abr := jbs.ArtifactBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(artifact)}, &abr)).NotTo(HaveOccurred())
+	abr.Status.State = jbs.ArtifactBuildStateFailed
+	g.Expect(client.Status().Update(ctx, &abr)).NotTo(HaveOccurred())
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(api.requests).To(Equal(2))
+	g.Expect(api.body).To(HaveKeyWithValue("state", CommitStatusFailure))
+	g.Expect(api.body).To(HaveKeyWithValue("description", "0/1 dependencies rebuilt, 1 failed"))
+}
+
+func TestReportCommitStatusWithoutToken(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	cb := defaultComponentBuild()
+	cb.Spec.SCMURL = "https://github.com/test/test.git"
+	g.Expect(client.Create(context.TODO(), &cb)).NotTo(HaveOccurred())
+	//without credentials for the host nothing is reported
+	g.Expect(reconciler.reportCommitStatus(context.TODO(), ctrl.Log, &cb)).To(Succeed())
+	g.Expect(cb.Status.CommitStatus).To(BeNil())
+}
+
+func TestReportCommitStatusUnknownHost(t *testing.T) {
+	g := NewGomegaWithT(t)
+	api := &notificationServer{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	secret := v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultNotifierSecret, Namespace: namespace}, Data: map[string][]byte{NotifierTokenKey: []byte("secret")}}
+	client, reconciler := setupClientAndReconciler(&secret)
+	ctx := context.TODO()
+	cb := defaultComponentBuild()
+	cb.Spec.SCMURL = "https://evilgithub.example/test/test.git"
+	cb.Spec.Tag = testSHA
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	//a host that only contains the name of a provider is not recognised
+	reporter, err := reconciler.newCommitStatusReporter(ctx, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reporter).To(BeNil())
+
+	//a server that is recognised from the PR URL does not get the token, as it is not an allowed host
+	cb.Spec.SCMURL = server.URL + "/test/test.git"
+	cb.Spec.PRURL = server.URL + "/test/test/pull/1"
+	g.Expect(reconciler.reportCommitStatus(ctx, ctrl.Log, &cb)).To(Succeed())
+	g.Expect(api.requests).To(BeZero())
+	g.Expect(cb.Status.CommitStatus).To(BeNil())
+
+	//it does get its own entry in the git credentials
+	secret.Data[GitCredentialsKey] = []byte(strings.Replace(server.URL, "http://", "http://user:credentials@", 1) + "\n")
+	g.Expect(client.Update(ctx, &secret)).NotTo(HaveOccurred())
+	g.Expect(reconciler.reportCommitStatus(ctx, ctrl.Log, &cb)).To(Succeed())
+	g.Expect(api.requests).To(Equal(1))
+	g.Expect(api.path).To(Equal("/api/v3/repos/test/test/statuses/" + testSHA))
+	g.Expect(api.header.Get("Authorization")).To(Equal("Bearer credentials"))
+}
@@ -3,31 +3,26 @@ package componentbuild
 import (
 	"context"
 	"fmt"
-	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha1"
-	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/apheleia-project/apheleia/pkg/config"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
-	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
-	"knative.dev/pkg/apis"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
-	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
 )
 
 const (
-	//TODO eventually we'll need to decide if we want to make this tuneable
-	contextTimeout      = 300 * time.Second
 	DeployTaskLabel     = "apheleia.io/deploy-task"
 	NotifyPipelineLabel = "apheleia.io/notify-pipeline"
 	ApheleiaConfig      = "apheleia-config"
@@ -40,138 +35,195 @@ type ReconcileArtifactBuild struct {
 	client        client.Client
 	scheme        *runtime.Scheme
 	eventRecorder record.EventRecorder
+	// apiReader reads deployment credentials, and the latest version of a ComponentBuild when its status conflicts,
+	// directly from the API server rather than the cache
+	apiReader      client.Reader
+	filesystemRoot string
+	// config holds the settings of the controller, which can change between reconciles
+	config *config.Store
+	// deploys serialises the admission of deploys in each namespace between the workers
+	deploys *namespaceDeploys
 }
 
-func newReconciler(mgr ctrl.Manager) reconcile.Reconciler {
+func newReconciler(mgr ctrl.Manager, store *config.Store) *ReconcileArtifactBuild {
 	return &ReconcileArtifactBuild{
-		client:        mgr.GetClient(),
-		scheme:        mgr.GetScheme(),
-		eventRecorder: mgr.GetEventRecorderFor("ComponentBuild"),
+		client:         mgr.GetClient(),
+		scheme:         mgr.GetScheme(),
+		eventRecorder:  mgr.GetEventRecorderFor("ComponentBuild"),
+		apiReader:      mgr.GetAPIReader(),
+		filesystemRoot: FilesystemRoot,
+		config:         store,
+		deploys:        &namespaceDeploys{},
 	}
 }
 
 func (r *ReconcileArtifactBuild) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
 	// Set the ctx to be Background, as the top-level context for incoming requests.
 	var cancel context.CancelFunc
-	ctx, cancel = context.WithTimeout(ctx, contextTimeout)
+	ctx, cancel = context.WithTimeout(ctx, r.config.Get().ReconcileTimeout.Duration)
 	defer cancel()
-	log := ctrl.Log.WithName("artifactbuild").WithValues("request", request.NamespacedName)
+	log := ctrl.Log.WithName("componentbuild").WithValues("request", request.NamespacedName)
 
-	abr := jvmbs.ArtifactBuild{}
-	abrerr := r.client.Get(ctx, request.NamespacedName, &abr)
-	if abrerr != nil {
-		if !errors.IsNotFound(abrerr) {
-			log.Error(abrerr, "Reconcile key %s as artifactbuild unexpected error", request.NamespacedName.String())
-			return ctrl.Result{}, abrerr
-		}
-	}
-
-	cb := v1alpha1.ComponentBuild{}
-	cberr := r.client.Get(ctx, request.NamespacedName, &cb)
-	if cberr != nil {
-		if !errors.IsNotFound(cberr) {
-			log.Error(cberr, "Reconcile key %s as componentbuild unexpected error", request.NamespacedName.String())
-			return ctrl.Result{}, cberr
-		}
-	}
-
-	tr := v1beta1.TaskRun{}
-	trerr := r.client.Get(ctx, request.NamespacedName, &tr)
-	if trerr != nil {
-		if !errors.IsNotFound(trerr) {
-			log.Error(trerr, "Reconcile key %s as taskrun unexpected error", request.NamespacedName.String())
-			return ctrl.Result{}, trerr
+	cb := v1alpha2.ComponentBuild{}
+	err := r.client.Get(ctx, request.NamespacedName, &cb)
+	if err == nil {
+		var result reconcile.Result
+		result, err = r.handleComponentBuildReceived(ctx, log, &cb)
+		if err == nil {
+			return result, nil
 		}
 	}
-	pr := v1beta1.PipelineRun{}
-	prerr := r.client.Get(ctx, request.NamespacedName, &pr)
-	if prerr != nil {
-		if !errors.IsNotFound(prerr) {
-			log.Error(prerr, "Reconcile key %s as pipelinerun unexpected error", request.NamespacedName.String())
-			return ctrl.Result{}, prerr
-		}
+	if errors.IsNotFound(err) {
+		log.Info("Reconcile key received not found error for componentbuild (probably deleted): " + request.NamespacedName.String())
+		return reconcile.Result{}, nil
 	}
-	if cberr != nil && abrerr != nil && trerr != nil && prerr != nil {
-		msg := "Reconcile key received not found errors for componentbuilds, artifactbuilds, pipelineruns, taskruns (probably deleted): " + request.NamespacedName.String()
-		log.Info(msg)
-		return ctrl.Result{}, nil
+	if errors.IsConflict(err) {
+		//the status is only computed again when it is written, anything else that conflicts is retried from the start
+		log.Info("ComponentBuild kept changing while it was reconciled, it will be retried: " + request.NamespacedName.String())
+		return reconcile.Result{Requeue: true}, nil
 	}
-
-	switch {
-	case cberr == nil:
-		return r.handleComponentBuildReceived(ctx, log, &cb)
-	case abrerr == nil:
-		return r.handleArtifactBuildReceived(ctx, log, &abr)
-	case trerr == nil:
-		return r.handleTaskRunReceived(ctx, log, &tr)
-	case prerr == nil:
-		return r.handlePipelineRunReceived(ctx, log, &pr)
-	}
-
-	return reconcile.Result{}, nil
+	return reconcile.Result{}, err
 }
 
-func (r *ReconcileArtifactBuild) handleComponentBuildReceived(ctx context.Context, log logr.Logger, cb *v1alpha1.ComponentBuild) (reconcile.Result, error) {
+func (r *ReconcileArtifactBuild) handleComponentBuildReceived(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild) (reconcile.Result, error) {
 	log.Info("Handling ComponentBuild", "name", cb.Name, "outstanding", cb.Status.Outstanding, "state", cb.Status.State)
+	if cb.DeletionTimestamp != nil {
+		return r.handleComponentBuildDeleted(ctx, log, cb)
+	}
+	if !controllerutil.ContainsFinalizer(cb, ComponentBuildFinalizer) {
+		original := cb.DeepCopy()
+		controllerutil.AddFinalizer(cb, ComponentBuildFinalizer)
+		err := r.patchFinalizers(ctx, cb, original)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	original := cb.DeepCopy()
 
 	//we need to make sure we have a deploy config. If not we don't do anything
-	cm := v1.ConfigMap{}
-	err := r.client.Get(ctx, types.NamespacedName{Namespace: cb.Namespace, Name: ApheleiaConfig}, &cm)
-	deployUrl := ""
-	deployDomain := ""
-	deployOwner := ""
+	target, reason, message, err := r.resolveDeploymentTarget(ctx, cb.Namespace)
 	if err != nil {
-		if !errors.IsNotFound(err) {
-			return reconcile.Result{}, err
-		}
-	} else {
-		deployUrl = cm.Data[MavenRepo]
-		deployOwner = cm.Data[AWSOwner]
-		deployDomain = cm.Data[AWSDomain]
-	}
-	const NoConfigMessage = "Some or all deployment config missing, please create a apheleia-config config map with the following keys: maven-repo, aws-owner, aws-domain"
-	if len(deployUrl) == 0 || len(deployOwner) == 0 || len(deployDomain) == 0 {
-		cb.Status.Message = NoConfigMessage
-		err := r.client.Status().Update(ctx, cb)
+		return reconcile.Result{}, err
+	}
+	cb.Status.ObservedGeneration = cb.Generation
+	if target == nil {
+		cb.Status.Message = message
+		setCondition(cb, v1alpha2.ConditionConfigValid, metav1.ConditionFalse, reason, message)
+		setCondition(cb, v1alpha2.ConditionReady, metav1.ConditionFalse, reason, message)
+		err := r.patchStatus(ctx, cb, original)
 		if err != nil {
 			return reconcile.Result{}, err
 		}
-		log.Info("No Deployment URL set, please create a apheleia-config config map with a maven-repo key, then retry the job.", "namespace", cb.Namespace)
+		log.Info("No usable deployment target, the ComponentBuild will continue once a DeploymentTarget or apheleia-config is created.", "namespace", cb.Namespace, "reason", message)
 		return reconcile.Result{}, nil
-	} else if cb.Status.Message == NoConfigMessage {
+	} else if meta.IsStatusConditionFalse(cb.Status.Conditions, v1alpha2.ConditionConfigValid) {
 		cb.Status.Message = ""
 	}
+	message = fmt.Sprintf("Deploying to %s", target.Spec.Location())
+	if reason == v1alpha2.ReasonConfigFound {
+		message += ", " + LegacyConfigMessage
+	}
+	setCondition(cb, v1alpha2.ConditionConfigValid, metav1.ConditionTrue, reason, message)
 
 	//iterate over the spec, and calculate the corresponding status
-	cb.Status.Outstanding = 0
-	cb.Status.ArtifactState = map[string]v1alpha1.ArtifactState{}
+	before := cb.Status.DeepCopy()
+	previous := referencedArtifactBuilds(cb)
+	cb.Status.ArtifactState = nil
+	current := map[string]bool{}
 	//TODO: Handle contaminates
-	for _, i := range cb.Spec.Artifacts {
-		existing := jvmbs.ArtifactBuild{}
-		key := types.NamespacedName{Namespace: cb.Namespace, Name: artifactbuild.CreateABRName(i)}
-		aberr := r.client.Get(ctx, key, &existing)
-		if aberr == nil || !errors.IsNotFound(aberr) {
-			cb.Status.ArtifactState[i] = r.artifactState(ctx, log, &existing)
-			state := cb.Status.ArtifactState[i]
-			if !state.Done() && !state.Failed {
-				cb.Status.Outstanding++
-			}
-			if state.Built && !state.Deployed {
-				derr := r.deployArtifact(ctx, log, &existing, deployUrl, deployOwner, deployDomain)
-				if derr != nil {
-					log.Error(derr, "Error deploying artifact", "name", existing.Name)
-				}
-			}
-		} else {
-			abr := jvmbs.ArtifactBuild{}
-			abr.Spec = jvmbs.ArtifactBuildSpec{GAV: i}
-			abr.Name = artifactbuild.CreateABRName(i)
-			abr.Namespace = cb.Namespace
-			err := r.client.Create(ctx, &abr)
-			if err != nil {
+	for _, a := range cb.Spec.Artifacts {
+		i := a.GAV()
+		abr, err := r.referenceArtifactBuild(ctx, cb, i)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		current[abr.Name] = true
+		cb.Status.SetArtifactState(r.artifactState(ctx, log, i, abr, target))
+	}
+	//artifacts that were removed from the spec are no longer referenced
+	var removed []string
+	for _, name := range previous {
+		if !current[name] {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) > 0 {
+		policy, err := r.retentionPolicy(ctx, log, cb.Namespace)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		for _, name := range removed {
+			if err := r.releaseArtifactBuild(ctx, log, cb, name, policy.artifacts); err != nil {
 				return reconcile.Result{}, err
 			}
-			cb.Status.ArtifactState[i] = r.artifactState(ctx, log, &abr)
+		}
+	}
+	retry, err := r.deployArtifacts(ctx, log, cb, target)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	updateState(cb)
+	var reportErr error
+	//the status is still updated if reporting the result fails, the error is returned afterwards so it is retried
+	if err := r.notifyResult(ctx, log, cb, original); err != nil {
+		log.Error(err, "Error notifying the result of the ComponentBuild", "name", cb.Name)
+		r.eventRecorder.Eventf(cb, v1.EventTypeWarning, "NotifyFailed", "Failed to report the result to %s: %s", cb.Spec.PRURL, err.Error())
+		reportErr = err
+	}
+	if err := r.reportCommitStatus(ctx, log, cb); err != nil {
+		log.Error(err, "Error setting the commit status of the ComponentBuild", "name", cb.Name)
+		r.eventRecorder.Eventf(cb, v1.EventTypeWarning, "CommitStatusFailed", "Failed to set the status of %s in %s: %s", cb.Spec.Tag, cb.Spec.SCMURL, err.Error())
+		if reportErr == nil {
+			reportErr = err
+		}
+	}
+	retriggerIn, err := r.retrigger(ctx, log, cb, original)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if retriggerIn > 0 && (retry == 0 || retriggerIn < retry) {
+		retry = retriggerIn
+	}
+	//events that cannot be delivered are kept in the status and retried, so they do not fail the reconcile
+	redeliver, err := r.emitEvents(ctx, log, cb, before)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if redeliver > 0 && (retry == 0 || redeliver < retry) {
+		retry = redeliver
+	}
+	updateConditions(cb)
+	err = r.writeStatus(ctx, log, cb, original, target)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	observeCompletion(cb, before.State)
+	if reportErr != nil {
+		return reconcile.Result{}, reportErr
+	}
+	policy, err := r.retentionPolicy(ctx, log, cb.Namespace)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	expiry, err := r.expireComponentBuild(ctx, log, cb, policy)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if expiry > 0 && (retry == 0 || expiry < retry) {
+		retry = expiry
+	}
+	if cb.Status.Queued > 0 && (retry == 0 || retry > deployQueueRecheckInterval) {
+		retry = deployQueueRecheckInterval
+	}
+	return reconcile.Result{RequeueAfter: retry}, nil
+}
+
+// updateState derives the number of outstanding artifacts and the state of the ComponentBuild from the state of its
+// artifacts
+func updateState(cb *v1alpha2.ComponentBuild) {
+	cb.Status.Outstanding = 0
+	for _, state := range cb.Status.ArtifactState {
+		if !state.Done() && !state.Failed && !state.DeployFailed {
 			cb.Status.Outstanding++
 		}
 	}
@@ -179,286 +231,325 @@ func (r *ReconcileArtifactBuild) handleComponentBuildReceived(ctx context.Contex
 		//completed, change the state
 		failed := false
 		for _, v := range cb.Status.ArtifactState {
-			if v.Failed {
+			if v.Failed || v.DeployFailed {
 				failed = true
 				break
 			}
 		}
 		if failed {
-			cb.Status.State = v1alpha1.ComponentBuildStateFailed
+			cb.Status.State = v1alpha2.ComponentBuildStateFailed
 		} else {
-			cb.Status.State = v1alpha1.ComponentBuildStateComplete
-		}
-
-		if (cb.Status.State == v1alpha1.ComponentBuildStateComplete || cb.Status.State == v1alpha1.ComponentBuildStateFailed) && !cb.Status.ResultNotified {
-			err := r.notifyResult(ctx, log, cb)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
+			cb.Status.State = v1alpha2.ComponentBuildStateComplete
 		}
 	} else {
 		//if there are still some outstanding we reset the notification state
-		cb.Status.State = v1alpha1.ComponentBuildStateInProgress
+		cb.Status.State = v1alpha2.ComponentBuildStateInProgress
 		cb.Status.ResultNotified = false
 	}
-	err = r.client.Status().Update(ctx, cb)
-	return reconcile.Result{}, err
 }
 
-func (r *ReconcileArtifactBuild) notifyResult(ctx context.Context, log logr.Logger, cb *v1alpha1.ComponentBuild) error {
-	if cb.Spec.PRURL == "" {
-		log.Info("Notifying ComponentBuild Status Skipped as PRURL is not set", "name", cb.Name, "scmUrl", cb.Spec.SCMURL, "state", cb.Status.State)
-		return nil
-	}
-	//first look for an existing TaskRun - If none are found create a new one
-	existing := v1beta1.PipelineRunList{}
-	listOpts := &client.ListOptions{
-		Namespace:     cb.Namespace,
-		LabelSelector: labels.SelectorFromSet(map[string]string{NotifyPipelineLabel: cb.Name}),
+// updateConditions derives the status conditions from the artifact state computed by handleComponentBuildReceived
+func updateConditions(cb *v1alpha2.ComponentBuild) {
+	total := len(cb.Status.ArtifactState)
+	built := 0
+	deployed := 0
+	failed := 0
+	deployFailed := 0
+	for _, v := range cb.Status.ArtifactState {
+		if v.Failed {
+			failed++
+		}
+		if v.DeployFailed {
+			deployFailed++
+		}
+		if v.Built {
+			built++
+		}
+		if v.Done() {
+			deployed++
+		}
 	}
-	err := r.client.List(ctx, &existing, listOpts)
-	if err != nil {
-		return err
+	switch {
+	case failed > 0:
+		setCondition(cb, v1alpha2.ConditionArtifactsBuilt, metav1.ConditionFalse, v1alpha2.ReasonBuildFailed, fmt.Sprintf("%d of %d artifacts failed to build", failed, total))
+	case built == total:
+		setCondition(cb, v1alpha2.ConditionArtifactsBuilt, metav1.ConditionTrue, v1alpha2.ReasonAllBuilt, fmt.Sprintf("%d/%d artifacts built", built, total))
+	default:
+		setCondition(cb, v1alpha2.ConditionArtifactsBuilt, metav1.ConditionFalse, v1alpha2.ReasonBuilding, fmt.Sprintf("%d/%d artifacts built", built, total))
 	}
-	for _, i := range existing.Items {
-		if i.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
-			return nil
+	switch {
+	case deployed == total:
+		setCondition(cb, v1alpha2.ConditionArtifactsDeployed, metav1.ConditionTrue, v1alpha2.ReasonAllDeployed, fmt.Sprintf("%d/%d artifacts deployed", deployed, total))
+	case deployFailed > 0:
+		setCondition(cb, v1alpha2.ConditionArtifactsDeployed, metav1.ConditionFalse, v1alpha2.ReasonDeployFailed, fmt.Sprintf("%d of %d artifacts failed to deploy", deployFailed, total))
+	case cb.Status.Queued > 0:
+		setCondition(cb, v1alpha2.ConditionArtifactsDeployed, metav1.ConditionFalse, v1alpha2.ReasonDeployQueued, fmt.Sprintf("%d/%d artifacts deployed, %d deploying, %d waiting for a free deploy slot", deployed, total, cb.Status.Deploying, cb.Status.Queued))
+	default:
+		setCondition(cb, v1alpha2.ConditionArtifactsDeployed, metav1.ConditionFalse, v1alpha2.ReasonDeploying, fmt.Sprintf("%d/%d artifacts deployed", deployed, total))
+	}
+	done := cb.Status.State == v1alpha2.ComponentBuildStateComplete || cb.Status.State == v1alpha2.ComponentBuildStateFailed
+	switch {
+	case cb.Spec.PRURL == "":
+		setCondition(cb, v1alpha2.ConditionNotified, metav1.ConditionFalse, v1alpha2.ReasonNoPullRequest, "No PR URL set, the result will not be reported")
+	case !done:
+		setCondition(cb, v1alpha2.ConditionNotified, metav1.ConditionFalse, v1alpha2.ReasonWaitingForCompletion, "")
+	case cb.Status.ResultNotified:
+		setCondition(cb, v1alpha2.ConditionNotified, metav1.ConditionTrue, v1alpha2.ReasonNotified, "")
+	default:
+		setCondition(cb, v1alpha2.ConditionNotified, metav1.ConditionFalse, v1alpha2.ReasonNotificationInProgress, "")
+	}
+	switch cb.Status.State {
+	case v1alpha2.ComponentBuildStateComplete:
+		setCondition(cb, v1alpha2.ConditionReady, metav1.ConditionTrue, v1alpha2.ReasonComplete, "All artifacts have been built and deployed")
+	case v1alpha2.ComponentBuildStateFailed:
+		message := fmt.Sprintf("%d of %d artifacts failed to build", failed, total)
+		if deployFailed > 0 {
+			message = fmt.Sprintf("%d of %d artifacts failed to build, %d failed to deploy", failed, total, deployFailed)
 		}
+		setCondition(cb, v1alpha2.ConditionReady, metav1.ConditionFalse, v1alpha2.ReasonFailed, message)
+	default:
+		setCondition(cb, v1alpha2.ConditionReady, metav1.ConditionFalse, v1alpha2.ReasonInProgress, fmt.Sprintf("%d artifacts outstanding", cb.Status.Outstanding))
 	}
-	tr := &v1beta1.PipelineRun{}
-	tr.GenerateName = cb.Name + "-notify-pipeline"
-	tr.Namespace = cb.Namespace
-	cerr := controllerutil.SetControllerReference(cb, tr, r.scheme)
-	if cerr != nil {
-		log.Error(cerr, fmt.Sprintf("Error setting controller reference for pipelinerun %s", tr.Name))
-	}
-	tr.Labels = map[string]string{NotifyPipelineLabel: cb.Name}
-	var notifierMessage string
-	if cb.Status.State == v1alpha1.ComponentBuildStateFailed {
-		var failedGavs []string
-		for gav, v := range cb.Status.ArtifactState {
-			if v.Failed {
-				failedGavs = append(failedGavs, gav)
-			}
-		}
-		notifierMessage = fmt.Sprintf("The following dependency builds have failed: %s.", strings.Join(failedGavs[:], ", "))
-	} else if cb.Status.State == v1alpha1.ComponentBuildStateComplete {
-		notifierMessage = "/retest Success all dependency builds have completed."
+}
+
+func setCondition(cb *v1alpha2.ComponentBuild, conditionType string, status metav1.ConditionStatus, reason string, message string) {
+	meta.SetStatusCondition(&cb.Status.Conditions, metav1.Condition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: cb.Generation,
+	})
+}
+
+// notifyResult reports the result of the ComponentBuild using the Notifier for the namespace. Notifiers that can edit
+// comments keep a single comment on the pull request up to date while the build progresses, the others report the
+// result once the build has finished. Results that are reported synchronously are marked as notified straight away,
+// otherwise this happens once the notify PipelineRun has succeeded.
+func (r *ReconcileArtifactBuild) notifyResult(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, original *v1alpha2.ComponentBuild) error {
+	done := cb.Status.State == v1alpha2.ComponentBuildStateComplete || cb.Status.State == v1alpha2.ComponentBuildStateFailed
+	if cb.Status.ResultNotified {
+		return nil
 	}
-	tr.Spec.PipelineRef = &v1beta1.PipelineRef{Name: "component-build-notifier"}
-	tr.Spec.Params = []v1beta1.Param{
-		{Name: "url", Value: v1beta1.ArrayOrString{StringVal: cb.Spec.PRURL, Type: v1beta1.ParamTypeString}},
-		{Name: "secret-key-ref", Value: v1beta1.ArrayOrString{StringVal: "jvm-build-git-secrets", Type: v1beta1.ParamTypeString}},
-		{Name: "message", Value: v1beta1.ArrayOrString{StringVal: notifierMessage, Type: v1beta1.ParamTypeString}},
+	if cb.Spec.PRURL == "" {
+		if done {
+			log.Info("Notifying ComponentBuild Status Skipped as PRURL is not set", "name", cb.Name, "scmUrl", cb.Spec.SCMURL, "state", cb.Status.State)
+		}
+		return nil
 	}
-	qty, err := resource.ParseQuantity("1Gi")
+	settings, err := r.namespaceSettings(ctx, cb.Namespace)
 	if err != nil {
 		return err
 	}
-	tr.Spec.Workspaces = []v1beta1.WorkspaceBinding{
-		{Name: "pr", VolumeClaimTemplate: &v1.PersistentVolumeClaim{
-			Spec: v1.PersistentVolumeClaimSpec{
-				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
-				Resources: v1.ResourceRequirements{
-					Requests: map[v1.ResourceName]resource.Quantity{"storage": qty},
-				},
-			},
-		}},
-	}
-	log.Info("Notifying ComponentBuild Status Update via PR Comment", "name", cb.Name, "scmUrl", cb.Spec.SCMURL, "PRURL", cb.Spec.PRURL, "state", cb.Status.State)
-	return r.client.Create(ctx, tr)
-}
-
-func (r *ReconcileArtifactBuild) deployArtifact(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild, deployUrl string, owner string, domain string) error {
-	// TODO: We should throttle the creation of deploy tasks so we dont swamp the cluster
-	// We also need to review the relationship between deploy tasks, dependencybuilds and rebuiltartifacts
-	db := r.getDependencyBuild(ctx, abr)
-	if db != nil && db.Annotations["io.aphelia/deployed"] == "" {
-		existing := v1beta1.TaskRunList{}
-		listOpts := &client.ListOptions{
-			Namespace:     abr.Namespace,
-			LabelSelector: labels.SelectorFromSet(map[string]string{DeployTaskLabel: db.Name}),
-		}
-		err := r.client.List(ctx, &existing, listOpts)
+	notification := settings.GetNotification()
+	//invalid templates are reported in the status and the default template is used instead
+	message, err := notificationMessage(notification, cb)
+	if err != nil {
+		log.Info("Invalid notification template, using the default", "name", cb.Name, "error", err.Error())
+		setCondition(cb, v1alpha2.ConditionTemplatesValid, metav1.ConditionFalse, v1alpha2.ReasonInvalidTemplate, err.Error())
+	} else {
+		setCondition(cb, v1alpha2.ConditionTemplatesValid, metav1.ConditionTrue, v1alpha2.ReasonTemplatesValid, "")
+	}
+	body := progressComment(r.config.Get().ConsoleURL, cb, message)
+	if !progressUpToDate(cb, body) {
+		notifier, err := r.newNotifier(ctx, cb, notification)
 		if err != nil {
 			return err
 		}
-		for _, i := range existing.Items {
-			if i.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+		reporter, ok := notifier.(ProgressReporter)
+		if !ok {
+			if !done {
 				return nil
 			}
+			notified, err := notifier.Notify(ctx, log, cb, message)
+			observeNotification(cb.Namespace, notifier, err)
+			if err != nil || !notified {
+				return err
+			}
+		} else {
+			err := r.reportProgress(ctx, log, cb, original, reporter, body)
+			observeNotification(cb.Namespace, notifier, err)
+			if err != nil {
+				return err
+			}
 		}
-		tr := &v1beta1.TaskRun{}
-		tr.GenerateName = abr.Name + "-deploy-task"
-		tr.Namespace = abr.Namespace
-		orerr := controllerutil.SetOwnerReference(abr, tr, r.scheme)
-		if orerr != nil {
-			log.Error(orerr, fmt.Sprintf("Error handling taskrun %s", tr.Name))
-		}
-		tr.Labels = map[string]string{DeployTaskLabel: db.Name}
-		tr.Spec.TaskRef = &v1beta1.TaskRef{Name: "apheleia-deploy", Kind: v1beta1.ClusterTaskKind}
-		tr.Spec.Params = []v1beta1.Param{
-			{Name: "DOMAIN", Value: v1beta1.ArrayOrString{StringVal: domain, Type: v1beta1.ParamTypeString}},
-			{Name: "OWNER", Value: v1beta1.ArrayOrString{StringVal: owner, Type: v1beta1.ParamTypeString}},
-			{Name: "REPO", Value: v1beta1.ArrayOrString{StringVal: deployUrl, Type: v1beta1.ParamTypeString}},
-			{Name: "FORCE", Value: v1beta1.ArrayOrString{StringVal: "false", Type: v1beta1.ParamTypeString}},
-			{Name: "ARTIFACT", Value: v1beta1.ArrayOrString{StringVal: abr.Name, Type: v1beta1.ParamTypeString}},
-		}
-		return r.client.Create(ctx, tr)
+	}
+	if done {
+		log.Info("Setting resultNotified: True for ComponentBuild Status", "name", cb.Name)
+		cb.Status.ResultNotified = true
 	}
 	return nil
 }
-func (r *ReconcileArtifactBuild) handleArtifactBuildReceived(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild) (reconcile.Result, error) {
-	log.Info("Handling ArtifactBuild", "name", abr.Name, "state", abr.Status.State)
-	cbList := v1alpha1.ComponentBuildList{}
-	err := r.client.List(ctx, &cbList, client.InNamespace(abr.Namespace))
+
+// deployArtifacts starts the deploys of the built artifacts that can be admitted from the deploy queue, and records
+// which artifacts are deploying, queued or failed in the status. It returns how long until the next failed deploy
+// can be retried, or zero if there is none.
+func (r *ReconcileArtifactBuild) deployArtifacts(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, target *v1alpha2.DeploymentTarget) (time.Duration, error) {
+	//deploys are throttled per namespace, so built artifacts wait in a queue for a free slot. The namespace is only
+	//locked while deploys are admitted, they run after it is unlocked.
+	unlock := r.deploys.lock(cb.Namespace)
+	queue, err := r.loadDeployQueue(ctx, cb, target)
 	if err != nil {
-		return reconcile.Result{}, err
+		unlock()
+		return 0, err
+	}
+	var pending []string
+	var batchClaim *deployClaim
+	batch := map[string]bool{}
+	if queue.batched {
+		pending = queue.pending(cb)
+		for _, name := range pending {
+			batch[name] = queue.queued(cb.Name)
+		}
+		if queue.admit(cb.Name) {
+			batchClaim = r.deploys.claim(queue, cb.Namespace, true, pending...)
+		}
 	}
-	for _, i := range cbList.Items {
-		_, exists := i.Status.ArtifactState[abr.Spec.GAV]
-		if exists {
-			cbItem := i
-			_, cberr := r.handleComponentBuildReceived(ctx, log, &cbItem)
-			if cberr != nil {
-				log.Error(cberr, fmt.Sprintf("Error handling componentbuild %s", i.Name))
+	admitted := map[string]*deployClaim{}
+	var retry time.Duration
+	for idx := range cb.Status.ArtifactState {
+		state := &cb.Status.ArtifactState[idx]
+		if state.Built && !state.Deployed {
+			record := queue.record(state.ArtifactBuild)
+			state.DeployAttempts = record.Attempts
+			state.LastDeployFailure = record.LastFailure
+			state.DeployFailed = queue.deployFailed(state.ArtifactBuild)
+			if wait := queue.retryIn(state.ArtifactBuild); wait > 0 && (retry == 0 || wait < retry) {
+				retry = wait
 			}
+			state.Deploying = queue.deploying(state.ArtifactBuild)
+			if !queue.batched && !state.Deploying && queue.admit(state.ArtifactBuild) {
+				admitted[state.ArtifactBuild] = r.deploys.claim(queue, cb.Namespace, false, state.ArtifactBuild)
+			}
+			state.Queued = queue.queued(state.ArtifactBuild) || batch[state.ArtifactBuild]
 		}
 	}
-	return reconcile.Result{}, nil
-}
+	unlock()
 
-func (r *ReconcileArtifactBuild) handleTaskRunReceived(ctx context.Context, log logr.Logger, tr *v1beta1.TaskRun) (reconcile.Result, error) {
-	log.Info("Handling TaskRun", "name", tr.Name)
-	if tr.Status.CompletionTime == nil || tr.Labels[DeployTaskLabel] == "" {
-		return reconcile.Result{}, nil
-	}
-	ownerRefs := tr.GetOwnerReferences()
-	if len(ownerRefs) == 0 {
-		msg := "taskrun missing onwerrefs %s:%s"
-		r.eventRecorder.Eventf(tr, v1.EventTypeWarning, msg, tr.Namespace, tr.Name)
-		log.Info(msg, tr.Namespace, tr.Name)
-		return reconcile.Result{}, nil
-	}
-	ownerName := ""
-	for _, ownerRef := range ownerRefs {
-		if strings.EqualFold(ownerRef.Kind, "artifactbuild") || strings.EqualFold(ownerRef.Kind, "artifactbuilds") {
-			ownerName = ownerRef.Name
-			break
+	batchDeploying := map[string]bool{}
+	if batchClaim != nil {
+		derr := r.deployBatch(ctx, log, cb, target, pending)
+		r.deploys.finish(batchClaim)
+		if derr != nil {
+			log.Error(derr, "Error deploying artifacts", "name", cb.Name)
+		} else {
+			for _, name := range pending {
+				batchDeploying[name] = true
+			}
 		}
 	}
-	if len(ownerName) == 0 {
-		msg := "taskrun missing artifactbuild ownerrefs %s:%s"
-		r.eventRecorder.Eventf(tr, v1.EventTypeWarning, "MissingOwner", msg, tr.Namespace, tr.Name)
-		log.Info(msg, tr.Namespace, tr.Name)
-		return reconcile.Result{}, nil
-	}
-
-	key := types.NamespacedName{Namespace: tr.Namespace, Name: ownerName}
-	ab := jvmbs.ArtifactBuild{}
-	err := r.client.Get(ctx, key, &ab)
-	if err != nil {
-		msg := "get for taskrun %s:%s owning artifact build %s:%s yielded error %s"
-		r.eventRecorder.Eventf(tr, v1.EventTypeWarning, msg, tr.Namespace, tr.Name, tr.Namespace, ownerName, err.Error())
-		log.Error(err, fmt.Sprintf(msg, tr.Namespace, tr.Name, tr.Namespace, ownerName, err.Error()))
-		return reconcile.Result{}, err
-	}
-	if tr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
-		db := r.getDependencyBuild(ctx, &ab)
-		if db != nil {
-			if db.Annotations == nil {
-				db.Annotations = map[string]string{}
+	cb.Status.Deploying = 0
+	cb.Status.Queued = 0
+	for idx := range cb.Status.ArtifactState {
+		state := &cb.Status.ArtifactState[idx]
+		if batchDeploying[state.ArtifactBuild] {
+			state.Deploying = true
+			state.Queued = false
+		}
+		if claim := admitted[state.ArtifactBuild]; claim != nil {
+			abr := jvmbs.ArtifactBuild{}
+			derr := r.client.Get(ctx, types.NamespacedName{Namespace: cb.Namespace, Name: state.ArtifactBuild}, &abr)
+			if derr == nil {
+				state.Deployed, derr = r.deployArtifact(ctx, log, &abr, target)
 			}
-			db.Annotations["io.aphelia/deployed"] = "true"
-			err := r.client.Update(ctx, db)
-			if err != nil {
-				log.Error(err, fmt.Sprintf("Error updating dependency build with deploy annotation %s", db.Name))
+			r.deploys.finish(claim)
+			if derr != nil {
+				log.Error(derr, "Error deploying artifact", "name", state.ArtifactBuild)
+			} else {
+				state.Deploying = !state.Deployed
 			}
 		}
+		if state.Deploying {
+			cb.Status.Deploying++
+		}
+		if state.Queued {
+			cb.Status.Queued++
+		}
 	}
-	return r.handleArtifactBuildReceived(ctx, log, &ab)
+	return retry, nil
 }
 
-func (r *ReconcileArtifactBuild) handlePipelineRunReceived(ctx context.Context, log logr.Logger, pr *v1beta1.PipelineRun) (reconcile.Result, error) {
-	log.Info("Handling PipelineRun", "name", pr.Name)
-	if pr.Labels["tekton.dev/pipeline"] != "component-build-notifier" {
-		return reconcile.Result{}, nil
-	}
-	if pr.Status.CompletionTime == nil {
-		return reconcile.Result{}, nil
+// deployBatch deploys the given artifacts of the ComponentBuild together
+func (r *ReconcileArtifactBuild) deployBatch(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, target *v1alpha2.DeploymentTarget, artifacts []string) error {
+	deployer, err := r.newDeployer(ctx, target)
+	if err != nil {
+		return err
 	}
-	ownerRefs := pr.GetOwnerReferences()
-	if len(ownerRefs) == 0 {
-		msg := "pipelinerun missing onwerrefs %s:%s"
-		r.eventRecorder.Eventf(pr, v1.EventTypeWarning, msg, pr.Namespace, pr.Name)
-		log.Info(msg, pr.Namespace, pr.Name)
-		return reconcile.Result{}, nil
+	batchDeployer, ok := deployer.(BatchDeployer)
+	if !ok {
+		return fmt.Errorf("%s repositories do not support batched deploys", target.Spec.GetRepositoryKind())
 	}
-	ownerName := ""
-	for _, ownerRef := range ownerRefs {
-		if strings.EqualFold(ownerRef.Kind, "componentbuild") || strings.EqualFold(ownerRef.Kind, "componentbuilds") {
-			ownerName = ownerRef.Name
-			break
+	//the records must exist before the TaskRun, so its result can be recorded when it completes
+	for _, name := range artifacts {
+		ra := jvmbs.RebuiltArtifact{}
+		err := r.client.Get(ctx, types.NamespacedName{Namespace: cb.Namespace, Name: name}, &ra)
+		if err != nil {
+			return err
+		}
+		_, err = r.ensureDeploymentRecord(ctx, &ra, target.Name)
+		if err != nil {
+			return err
 		}
 	}
-	if len(ownerName) == 0 {
-		msg := "pipelinerun missing componentbuild ownerrefs %s:%s"
-		r.eventRecorder.Eventf(pr, v1.EventTypeWarning, "MissingOwner", msg, pr.Namespace, pr.Name)
-		log.Info(msg, pr.Namespace, pr.Name)
-		return reconcile.Result{}, nil
-	}
+	return batchDeployer.DeployBatch(ctx, log, cb, artifacts)
+}
 
-	key := types.NamespacedName{Namespace: pr.Namespace, Name: ownerName}
-	cb := v1alpha1.ComponentBuild{}
-	err := r.client.Get(ctx, key, &cb)
+// deployArtifact deploys the rebuilt artifact using the Deployer for the target, it returns true if the artifact was
+// deployed synchronously
+func (r *ReconcileArtifactBuild) deployArtifact(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild, target *v1alpha2.DeploymentTarget) (bool, error) {
+	ra := r.getRebuiltArtifact(ctx, abr)
+	if ra == nil {
+		return false, nil
+	}
+	record, err := r.ensureDeploymentRecord(ctx, ra, target.Name)
+	if err != nil || record.Status.Deployed {
+		return err == nil, err
+	}
+	deployer, err := r.newDeployer(ctx, target)
 	if err != nil {
-		msg := "get for pipelinerun %s:%s owning component build %s:%s yielded error %s"
-		r.eventRecorder.Eventf(pr, v1.EventTypeWarning, msg, pr.Namespace, pr.Name, pr.Namespace, ownerName, err.Error())
-		log.Error(err, fmt.Sprintf(msg, pr.Namespace, pr.Name, pr.Namespace, ownerName, err.Error()))
-		return reconcile.Result{}, err
+		return false, err
 	}
-	if pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
-		cb.Status.ResultNotified = true
-		log.Info("Setting resultNotified: True for ComponentBuild Status", "name", cb.Name)
-		return reconcile.Result{}, r.client.Status().Update(ctx, &cb)
+	started := time.Now()
+	deployed, err := deployer.Deploy(ctx, log, abr, ra, record)
+	//deployers that run a TaskRun return straight away, their result is recorded when the TaskRun completes
+	if err != nil || deployed {
+		observeDeploy(abr.Namespace, err == nil, time.Since(started))
+	}
+	if err != nil {
+		if ferr := r.recordDeployFailure(ctx, record, time.Now(), err.Error()); ferr != nil {
+			log.Error(ferr, fmt.Sprintf("Error recording deploy failure on deployment record %s", record.Name))
+		}
+		return false, err
+	}
+	if !deployed {
+		return false, nil
 	}
-	return r.handleComponentBuildReceived(ctx, log, &cb)
+	log.Info("Deployed artifact", "name", abr.Name, "repository", target.Spec.Location())
+	return true, r.markDeployed(ctx, record, target.Spec.Location(), "")
 }
 
-func (r *ReconcileArtifactBuild) artifactState(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild) v1alpha1.ArtifactState {
+// artifactState calculates the state of the artifact, it is deployed once the DeploymentRecord for the target says so
+func (r *ReconcileArtifactBuild) artifactState(ctx context.Context, log logr.Logger, gav string, abr *jvmbs.ArtifactBuild, target *v1alpha2.DeploymentTarget) v1alpha2.ArtifactState {
 	failed := abr.Status.State == jvmbs.ArtifactBuildStateFailed || abr.Status.State == jvmbs.ArtifactBuildStateMissing
 	built := abr.Status.State == jvmbs.ArtifactBuildStateComplete
 	deployed := false
 	if built {
-		db := r.getDependencyBuild(ctx, abr)
-		if db != nil && db.Annotations["io.aphelia/deployed"] == "true" {
-			deployed = true
+		record, err := r.getDeploymentRecord(ctx, abr.Namespace, deploymentRecordName(abr.Name, target.Name))
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Error reading deployment record for %s", abr.Name))
 		}
+		deployed = record != nil && record.Status.Deployed
 	}
-	return v1alpha1.ArtifactState{ArtifactBuild: abr.Name, Failed: failed, Built: built, Deployed: deployed}
+	return v1alpha2.ArtifactState{GAV: gav, ArtifactBuild: abr.Name, Failed: failed, Built: built, Deployed: deployed}
 }
 
-func (r *ReconcileArtifactBuild) getDependencyBuild(ctx context.Context, abr *jvmbs.ArtifactBuild) *jvmbs.DependencyBuild {
+// getRebuiltArtifact returns the RebuiltArtifact for the ArtifactBuild, or nil if it has not been rebuilt
+func (r *ReconcileArtifactBuild) getRebuiltArtifact(ctx context.Context, abr *jvmbs.ArtifactBuild) *jvmbs.RebuiltArtifact {
 	key := types.NamespacedName{Namespace: abr.Namespace, Name: abr.Name}
 	ra := jvmbs.RebuiltArtifact{}
 	err := r.client.Get(ctx, key, &ra)
 	if err != nil {
 		return nil
 	}
-	ownerReferences := ra.GetOwnerReferences()
-	depenencyBuildName := ""
-	for _, ownerReference := range ownerReferences {
-		if ownerReference.Kind == "DependencyBuild" {
-			depenencyBuildName = ownerReference.Name
-		}
-	}
-	if depenencyBuildName != "" {
-		key := types.NamespacedName{Namespace: abr.Namespace, Name: depenencyBuildName}
-		da := jvmbs.DependencyBuild{}
-		err := r.client.Get(ctx, key, &da)
-		if err == nil {
-			return &da
-		}
-	}
-	return nil
+	return &ra
 }
@@ -2,10 +2,12 @@ package componentbuild
 
 import (
 	"context"
-	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha1"
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
 	aph "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/scheme"
+	"github.com/apheleia-project/apheleia/pkg/config"
 	. "github.com/onsi/gomega"
 	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 	controllerruntime "sigs.k8s.io/controller-runtime"
@@ -20,6 +22,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/tools/record"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -48,7 +51,7 @@ func setupClientAndReconciler(objs ...runtimeclient.Object) (runtimeclient.Clien
 	cm.Name = ApheleiaConfig
 	cm.Data = map[string]string{MavenRepo: DummyRepo, AWSDomain: DummyDomain, AWSOwner: DummyOwner}
 	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithObjects(&cm).Build()
-	reconciler := &ReconcileArtifactBuild{client: client, scheme: scheme, eventRecorder: &record.FakeRecorder{}}
+	reconciler := &ReconcileArtifactBuild{client: client, scheme: scheme, eventRecorder: &record.FakeRecorder{}, apiReader: client, deploys: &namespaceDeploys{}}
 	return client, reconciler
 }
 
@@ -92,7 +95,7 @@ func TestArtifactBuildCreation(t *testing.T) {
 	g.Expect(controllerutil.SetOwnerReference(&db, &ra, client.Scheme())).NotTo(HaveOccurred())
 	g.Expect(client.Create(ctx, &ra)).NotTo(HaveOccurred())
 
-	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: abrName})
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
 	g.Expect(err).NotTo(HaveOccurred())
 
 	//now lets look for a taskrun
@@ -104,9 +107,12 @@ func TestArtifactBuildCreation(t *testing.T) {
 	for _, p := range tr.Spec.Params {
 		paramMap[p.Name] = p.Value.StringVal
 	}
+	g.Expect(tr.Spec.TaskRef.Name).To(Equal(config.DefaultDeployTask))
 	g.Expect(paramMap["REPO"]).To(Equal(DummyRepo))
 	g.Expect(paramMap["DOMAIN"]).To(Equal(DummyDomain))
 	g.Expect(paramMap["OWNER"]).To(Equal(DummyOwner))
+	g.Expect(paramMap["CREDENTIALS_SECRET"]).To(Equal(v1alpha2.DefaultCredentialsSecret))
+	g.Expect(paramMap["IMAGE_SECRET"]).To(Equal(v1alpha2.DefaultImageSecret))
 
 	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
 	tr.Status.SetCondition(&apis.Condition{
@@ -116,23 +122,242 @@ func TestArtifactBuildCreation(t *testing.T) {
 	})
 	g.Expect(client.Status().Update(ctx, &tr)).NotTo(HaveOccurred())
 
-	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: tr.Namespace, Name: tr.Name}})
-	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(reconcileTaskRun(ctx, client, reconciler, types.NamespacedName{Namespace: tr.Namespace, Name: tr.Name})).NotTo(HaveOccurred())
 	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: cb.Namespace, Name: cb.Name}, &cb)).NotTo(HaveOccurred())
 	g.Expect(cb.Status.State).To(Equal("ComponentBuildComplete"))
+	g.Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, v1alpha2.ConditionReady)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, v1alpha2.ConditionArtifactsBuilt)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, v1alpha2.ConditionArtifactsDeployed)).To(BeTrue())
+	g.Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, v1alpha2.ConditionConfigValid)).To(BeTrue())
+	g.Expect(meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionNotified).Reason).To(Equal(v1alpha2.ReasonNoPullRequest))
+
+}
+
+// reconcileTaskRun reconciles the TaskRun, then the ComponentBuilds in the namespace as the watches on the
+// DeploymentRecords and TaskRuns would
+func reconcileTaskRun(ctx context.Context, client runtimeclient.Client, reconciler *ReconcileArtifactBuild, tr types.NamespacedName) error {
+	_, err := (&ReconcileTaskRun{ReconcileArtifactBuild: reconciler}).Reconcile(ctx, reconcile.Request{NamespacedName: tr})
+	if err != nil {
+		return err
+	}
+	cbList := v1alpha2.ComponentBuildList{}
+	if err := client.List(ctx, &cbList, runtimeclient.InNamespace(tr.Namespace)); err != nil {
+		return err
+	}
+	for _, cb := range cbList.Items {
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cb.Namespace, Name: cb.Name}})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestTaskRunWithComponentBuildName(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	createBuiltArtifact(g, client, artifact, time.Now())
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	//the deploy TaskRun has the same name as the ComponentBuild, each controller only handles its own kind
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(trl.Items).To(HaveLen(1))
+	tr := trl.Items[0].DeepCopy()
+	g.Expect(client.Delete(ctx, &trl.Items[0])).NotTo(HaveOccurred())
+	tr.ObjectMeta = metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: tr.Labels, Annotations: tr.Annotations, OwnerReferences: tr.OwnerReferences}
+	g.Expect(client.Create(ctx, tr)).NotTo(HaveOccurred())
+	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	tr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue})
+	g.Expect(client.Status().Update(ctx, tr)).NotTo(HaveOccurred())
 
+	g.Expect(reconcileTaskRun(ctx, client, reconciler, cbName)).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateComplete))
+	g.Expect(cb.Status.GetArtifactState(artifact).Deployed).To(BeTrue())
+}
+
+func TestDeployLongArtifactName(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	gav := "org.example.some.rather.long.group:an-artifact-with-a-rather-long-name:1.0.0.redhat-00001"
+	abrName := createBuiltArtifact(g, client, gav, time.Now())
+	cb := defaultComponentBuild()
+	cb.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV(gav)}
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	//the record name does not fit in a label, so the label holds a hash and the name is in an annotation
+	recordName := deploymentRecordName(abrName, ApheleiaConfig)
+	g.Expect(len(recordName)).To(BeNumerically(">", validation.LabelValueMaxLength))
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(trl.Items).To(HaveLen(1))
+	tr := trl.Items[0]
+	g.Expect(validation.IsValidLabelValue(tr.Labels[DeployTaskLabel])).To(BeEmpty())
+	g.Expect(tr.Annotations[DeploymentRecordAnnotation]).To(Equal(recordName))
+
+	//a running deploy is not started again
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(trl.Items).To(HaveLen(1))
+
+	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	tr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue})
+	g.Expect(client.Status().Update(ctx, &tr)).NotTo(HaveOccurred())
+	g.Expect(reconcileTaskRun(ctx, client, reconciler, types.NamespacedName{Namespace: tr.Namespace, Name: tr.Name})).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateComplete))
+	g.Expect(cb.Status.GetArtifactState(gav).Deployed).To(BeTrue())
+}
+
+func TestConditionsInProgress(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	cb := defaultComponentBuild()
+	cb.Generation = 2
+	ctx := context.TODO()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).NotTo(HaveOccurred())
+
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateInProgress))
+	g.Expect(cb.Status.ObservedGeneration).To(Equal(cb.Generation))
+	ready := meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionReady)
+	g.Expect(ready).NotTo(BeNil())
+	g.Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(ready.Reason).To(Equal(v1alpha2.ReasonInProgress))
+	g.Expect(ready.ObservedGeneration).To(Equal(cb.Generation))
+	built := meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionArtifactsBuilt)
+	g.Expect(built.Reason).To(Equal(v1alpha2.ReasonBuilding))
+	g.Expect(built.Message).To(Equal("0/1 artifacts built"))
+}
+
+func TestConditionsMissingConfig(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	cm := v1.ConfigMap{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ApheleiaConfig}, &cm)).NotTo(HaveOccurred())
+	g.Expect(client.Delete(ctx, &cm)).NotTo(HaveOccurred())
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).NotTo(HaveOccurred())
+
+	g.Expect(meta.IsStatusConditionFalse(cb.Status.Conditions, v1alpha2.ConditionConfigValid)).To(BeTrue())
+	g.Expect(meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionReady).Reason).To(Equal(v1alpha2.ReasonConfigMissing))
+
+	//a config map without the aws keys is still missing config
+	cm = v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ApheleiaConfig, Namespace: namespace}, Data: map[string]string{MavenRepo: DummyRepo}}
+	g.Expect(client.Create(ctx, &cm)).NotTo(HaveOccurred())
+	requests := namespaceRequests(client, "ConfigMap")(&cm)
+	g.Expect(requests).To(Equal([]reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}))
+	_, err = reconciler.Reconcile(ctx, requests[0])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).NotTo(HaveOccurred())
+	g.Expect(meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionReady).Reason).To(Equal(v1alpha2.ReasonConfigMissing))
+	g.Expect(cb.Status.Message).To(Equal(NoConfigMessage))
+
+	//completing the config map reconciles the ComponentBuild, which continues without being retried
+	cm.Data[AWSDomain] = DummyDomain
+	cm.Data[AWSOwner] = DummyOwner
+	g.Expect(client.Update(ctx, &cm)).NotTo(HaveOccurred())
+	_, err = reconciler.Reconcile(ctx, requests[0])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).NotTo(HaveOccurred())
+	g.Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, v1alpha2.ConditionConfigValid)).To(BeTrue())
+	g.Expect(cb.Status.Message).To(BeEmpty())
+}
+
+func TestResolveDeploymentTarget(t *testing.T) {
+	usable := func(name string, status metav1.ConditionStatus) *v1alpha2.DeploymentTarget {
+		target := v1alpha2.DeploymentTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Generation: 1},
+			Spec: v1alpha2.DeploymentTargetSpec{
+				RepositoryURL:     "https://target.test/maven/",
+				CredentialsSecret: "target-aws",
+				CodeArtifact:      &v1alpha2.CodeArtifactSettings{Domain: "target-domain", Owner: "target-owner"},
+			},
+		}
+		meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: status, Reason: v1alpha2.ReasonSecretMissing, Message: "secret target-aws does not exist", ObservedGeneration: 1})
+		return &target
+	}
+	tests := []struct {
+		name    string
+		objs    []runtimeclient.Object
+		repo    string
+		reason  string
+		message string
+	}{
+		{name: "config map fallback", repo: DummyRepo, reason: v1alpha2.ReasonConfigFound},
+		{name: "target preferred", objs: []runtimeclient.Object{usable("test", metav1.ConditionTrue)}, repo: "https://target.test/maven/", reason: v1alpha2.ReasonTargetFound},
+		{name: "target not usable", objs: []runtimeclient.Object{usable("test", metav1.ConditionFalse)}, reason: v1alpha2.ReasonTargetNotUsable, message: "secret target-aws does not exist"},
+		{name: "target not checked", objs: []runtimeclient.Object{&v1alpha2.DeploymentTarget{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace}}}, reason: v1alpha2.ReasonTargetNotUsable, message: "not been checked"},
+		{name: "multiple targets", objs: []runtimeclient.Object{usable("one", metav1.ConditionTrue), usable("two", metav1.ConditionTrue)}, reason: v1alpha2.ReasonAmbiguousTarget, message: "exactly one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			_, reconciler := setupClientAndReconciler(tt.objs...)
+			target, reason, message, err := reconciler.resolveDeploymentTarget(context.TODO(), namespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(reason).To(Equal(tt.reason))
+			g.Expect(message).To(ContainSubstring(tt.message))
+			if tt.repo == "" {
+				g.Expect(target).To(BeNil())
+				return
+			}
+			g.Expect(target.Spec.RepositoryURL).To(Equal(tt.repo))
+		})
+	}
+}
+
+func TestLegacyConfigSettings(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	//the config map only configures the repository, which is reported so that the defaults are not a surprise
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	valid := meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionConfigValid)
+	g.Expect(valid.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(valid.Reason).To(Equal(v1alpha2.ReasonConfigFound))
+	g.Expect(valid.Message).To(ContainSubstring(LegacyConfigMessage))
+	settings, err := reconciler.namespaceSettings(ctx, namespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(*settings).To(Equal(v1alpha2.DeploymentTargetSpec{}))
+	policy, err := reconciler.retentionPolicy(ctx, controllerruntime.Log, namespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy).To(Equal(retentionPolicy{artifacts: v1alpha2.ArtifactRetentionRetain, keepRuns: -1}))
 }
 
-func defaultComponentBuild() v1alpha1.ComponentBuild {
-	return v1alpha1.ComponentBuild{
+func defaultComponentBuild() v1alpha2.ComponentBuild {
+	return v1alpha2.ComponentBuild{
 		ObjectMeta: controllerruntime.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
 		},
-		Spec: v1alpha1.ComponentBuildSpec{
+		Spec: v1alpha2.ComponentBuildSpec{
 			SCMURL:    "https://test.com/test.git",
 			Tag:       "1.0",
-			Artifacts: []string{artifact},
+			Artifacts: []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV(artifact)},
 		},
 	}
 }
@@ -1,53 +1,128 @@
 package componentbuild
 
 import (
-	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha1"
+	"context"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/apheleia-project/apheleia/pkg/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
 )
 
-func SetupNewReconcilerWithManager(mgr ctrl.Manager) error {
-	r := newReconciler(mgr)
-	return ctrl.NewControllerManagedBy(mgr).For(&v1alpha1.ComponentBuild{}).
+// SetupNewReconcilerWithManager adds a controller for each kind that is reconciled. Only the ComponentBuild controller
+// writes the status of ComponentBuilds, the other kinds are mapped to the ComponentBuilds they affect.
+func SetupNewReconcilerWithManager(mgr ctrl.Manager, store *config.Store) error {
+	r := newReconciler(mgr, store)
+	//the number of workers is fixed when the controllers are created, so it is not reloaded
+	options := controller.Options{MaxConcurrentReconciles: store.Get().MaxConcurrentReconciles}
+	//deployments used to be recorded with annotations on the DependencyBuilds
+	if err := mgr.Add(manager.RunnableFunc(r.deployAnnotationMigration)); err != nil {
+		return err
+	}
+	if err := metrics.Registry.Register(newComponentBuildCollector(mgr.GetClient())); err != nil {
+		return err
+	}
+	if err := setupIndexes(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	err := ctrl.NewControllerManagedBy(mgr).For(&v1alpha2.ComponentBuild{}).WithOptions(options).
 		Watches(&source.Kind{Type: &jvmbs.ArtifactBuild{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
-			artifactBuild := o.(*jvmbs.ArtifactBuild)
-			return []reconcile.Request{
-				{
-					NamespacedName: types.NamespacedName{
-						Name:      artifactBuild.Name,
-						Namespace: artifactBuild.Namespace,
-					},
-				},
+			//only the ComponentBuilds that depend on the artifact are reconciled
+			builds, err := artifactBuildComponentBuilds(context.Background(), mgr.GetClient(), o.(*jvmbs.ArtifactBuild))
+			if err != nil {
+				ctrl.Log.WithName("componentbuild").Error(err, "Unable to list ComponentBuilds for ArtifactBuild", "namespace", o.GetNamespace(), "name", o.GetName())
+				return nil
+			}
+			return requestsFor(builds)
+		})).
+		Watches(&source.Kind{Type: &v1alpha2.DeploymentRecord{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			//deploy results are recorded by the TaskRun controller, the RebuiltArtifact has the name of the ArtifactBuild
+			record := o.(*v1alpha2.DeploymentRecord)
+			builds, err := listComponentBuilds(context.Background(), mgr.GetClient(), record.Namespace, ArtifactBuildIndex, record.Spec.RebuiltArtifact)
+			if err != nil {
+				ctrl.Log.WithName("componentbuild").Error(err, "Unable to list ComponentBuilds for DeploymentRecord", "namespace", record.Namespace, "name", record.Name)
+				return nil
 			}
+			return requestsFor(builds)
 		})).
-		Watches(&source.Kind{Type: &v1beta1.PipelineRun{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
-			pipelineRun := o.(*v1beta1.PipelineRun)
-			return []reconcile.Request{
-				{
-					NamespacedName: types.NamespacedName{
-						Name:      pipelineRun.Name,
-						Namespace: pipelineRun.Namespace,
-					},
-				},
+		Watches(&source.Kind{Type: &jvmbs.RebuiltArtifact{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
+			//an artifact can only be deployed once it has been rebuilt, which may be after the ArtifactBuild completed
+			builds, err := rebuiltArtifactComponentBuilds(context.Background(), mgr.GetClient(), o.(*jvmbs.RebuiltArtifact))
+			if err != nil {
+				ctrl.Log.WithName("componentbuild").Error(err, "Unable to list ComponentBuilds for RebuiltArtifact", "namespace", o.GetNamespace(), "name", o.GetName())
+				return nil
 			}
+			return requestsFor(builds)
 		})).
+		//the result is notified once the notify pipeline of the ComponentBuild succeeds
+		Watches(&source.Kind{Type: &v1beta1.PipelineRun{}}, handler.EnqueueRequestsFromMapFunc(notifyPipelineRunRequests), builder.WithPredicates(notifyPipelineRunPredicates)).
 		Watches(&source.Kind{Type: &v1beta1.TaskRun{}}, handler.EnqueueRequestsFromMapFunc(func(o client.Object) []reconcile.Request {
 			taskRun := o.(*v1beta1.TaskRun)
-			return []reconcile.Request{
-				{
-					NamespacedName: types.NamespacedName{
-						Name:      taskRun.Name,
-						Namespace: taskRun.Namespace,
-					},
-				},
+			if taskRun.Status.CompletionTime == nil {
+				return nil
 			}
-		})).
+			//a finished deploy frees a slot, so ComponentBuilds with queued artifacts can continue
+			cbList := v1alpha2.ComponentBuildList{}
+			err := mgr.GetClient().List(context.Background(), &cbList, client.InNamespace(taskRun.Namespace))
+			if err != nil {
+				ctrl.Log.WithName("componentbuild").Error(err, "Unable to list ComponentBuilds for TaskRun", "namespace", taskRun.Namespace, "name", taskRun.Name)
+				return nil
+			}
+			var requests []reconcile.Request
+			for _, i := range cbList.Items {
+				if i.Status.Queued > 0 {
+					requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: i.Name, Namespace: i.Namespace}})
+				}
+			}
+			return requests
+		}), builder.WithPredicates(deployTaskRunPredicates)).
+		//a change to the deployment config can unblock every ComponentBuild in the namespace
+		Watches(&source.Kind{Type: &v1alpha2.DeploymentTarget{}}, handler.EnqueueRequestsFromMapFunc(namespaceRequests(mgr.GetClient(), "DeploymentTarget"))).
+		Watches(&source.Kind{Type: &v1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(namespaceRequests(mgr.GetClient(), "ConfigMap")), builder.WithPredicates(predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return o.GetName() == ApheleiaConfig
+		}))).
 		Complete(r)
+	if err != nil {
+		return err
+	}
+	err = ctrl.NewControllerManagedBy(mgr).Named("deploytaskrun").WithOptions(options).
+		For(&v1beta1.TaskRun{}, builder.WithPredicates(deployTaskRunPredicates)).
+		Complete(&ReconcileTaskRun{ReconcileArtifactBuild: r})
+	if err != nil {
+		return err
+	}
+	return ctrl.NewControllerManagedBy(mgr).Named("notifypipelinerun").WithOptions(options).
+		For(&v1beta1.PipelineRun{}, builder.WithPredicates(notifyPipelineRunPredicates)).
+		Complete(&ReconcilePipelineRun{ReconcileArtifactBuild: r})
+}
+
+// namespaceRequests returns a map function that reconciles every ComponentBuild in the namespace of the object
+func namespaceRequests(c client.Reader, kind string) handler.MapFunc {
+	return func(o client.Object) []reconcile.Request {
+		cbList := v1alpha2.ComponentBuildList{}
+		err := c.List(context.Background(), &cbList, client.InNamespace(o.GetNamespace()))
+		if err != nil {
+			ctrl.Log.WithName("componentbuild").Error(err, "Unable to list ComponentBuilds for "+kind, "namespace", o.GetNamespace(), "name", o.GetName())
+			return nil
+		}
+		return requestsFor(cbList.Items)
+	}
+}
+
+// isDeployTaskRun returns true if the TaskRun deploys one artifact or a batch of artifacts
+func isDeployTaskRun(tr *v1beta1.TaskRun) bool {
+	return tr.Labels[DeployTaskLabel] != "" || tr.Labels[BatchDeployTaskLabel] != ""
 }
@@ -0,0 +1,219 @@
+package componentbuild
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	imagename "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// FilesystemRoot is where the volume that Filesystem repositories are written to is mounted in the operator
+	FilesystemRoot = "/var/lib/apheleia/repositories"
+	// artifactsDir is the directory in the rebuilt artifact images that holds the maven repository
+	artifactsDir = "artifacts/"
+)
+
+// Deployer deploys a rebuilt artifact to a repository
+type Deployer interface {
+	// Deploy deploys the artifacts that were rebuilt for the given ArtifactBuild. It returns true if the artifacts
+	// have been deployed, or false if the deployment is still running and its result will be reported by a TaskRun.
+	// Deployers that deploy the files themselves add their checksums to the record, and skip the files that an
+	// earlier attempt has already recorded.
+	Deploy(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild, ra *jvmbs.RebuiltArtifact, record *v1alpha2.DeploymentRecord) (bool, error)
+}
+
+// BatchDeployer is implemented by deployers that can deploy all the artifacts of a ComponentBuild at once
+type BatchDeployer interface {
+	// DeployBatch starts a deployment of the named artifacts, the result is reported by a TaskRun
+	DeployBatch(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, artifacts []string) error
+}
+
+// newDeployer returns the Deployer for the kind of repository described by the target
+func (r *ReconcileArtifactBuild) newDeployer(ctx context.Context, target *v1alpha2.DeploymentTarget) (Deployer, error) {
+	kind := target.Spec.GetRepositoryKind()
+	if kind == v1alpha2.RepositoryKindCodeArtifact {
+		return &codeArtifactDeployer{client: r.client, scheme: r.scheme, target: target, task: r.config.Get().DeployTask}, nil
+	}
+	images, err := r.keychain(ctx, target.Namespace, target.Spec.GetImageSecret())
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case v1alpha2.RepositoryKindMaven:
+		deployer := &mavenDeployer{client: &http.Client{Timeout: mavenRequestTimeout}, url: target.Spec.RepositoryURL, images: images, saveRecord: r.saveRecord}
+		if credentials := target.Spec.GetCredentialsSecret(); credentials != "" {
+			secret, err := r.secret(ctx, target.Namespace, credentials)
+			if err != nil {
+				return nil, err
+			}
+			deployer.username = string(secret.Data[v1alpha2.MavenUsernameKey])
+			deployer.password = string(secret.Data[v1alpha2.MavenPasswordKey])
+		}
+		return deployer, nil
+	case v1alpha2.RepositoryKindFilesystem:
+		return &filesystemDeployer{root: filepath.Join(r.filesystemRoot, filepath.FromSlash(path.Clean(target.Spec.Filesystem.Path))), images: images, saveRecord: r.saveRecord}, nil
+	case v1alpha2.RepositoryKindOCI:
+		push, err := r.keychain(ctx, target.Namespace, target.Spec.GetCredentialsSecret())
+		if err != nil {
+			return nil, err
+		}
+		return &ociDeployer{repository: target.Spec.OCI.Repository, images: images, push: push}, nil
+	}
+	return nil, fmt.Errorf("unsupported repository kind %s", kind)
+}
+
+func (r *ReconcileArtifactBuild) secret(ctx context.Context, namespace string, name string) (*v1.Secret, error) {
+	secret := v1.Secret{}
+	err := r.apiReader.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &secret)
+	if err != nil {
+		return nil, err
+	}
+	return &secret, nil
+}
+
+// keychain returns a keychain that uses the registry credentials in the given docker config secret
+func (r *ReconcileArtifactBuild) keychain(ctx context.Context, namespace string, name string) (authn.Keychain, error) {
+	secret, err := r.secret(ctx, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	return newDockerConfigKeychain(secret.Data[v1.DockerConfigJsonKey])
+}
+
+// dockerConfigKeychain resolves registry credentials from the contents of a .dockerconfigjson file
+type dockerConfigKeychain struct {
+	auths map[string]authn.AuthConfig
+}
+
+func newDockerConfigKeychain(data []byte) (authn.Keychain, error) {
+	config := struct {
+		Auths map[string]authn.AuthConfig `json:"auths"`
+	}{}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unable to parse docker config: %w", err)
+	}
+	return &dockerConfigKeychain{auths: config.Auths}, nil
+}
+
+func (k *dockerConfigKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	for host, auth := range k.auths {
+		//keys can be plain hosts or URLs such as https://index.docker.io/v1/
+		if u, err := url.Parse(host); err == nil && u.Host != "" {
+			host = u.Host
+		}
+		if host == target.RegistryStr() {
+			return authn.FromConfig(auth), nil
+		}
+	}
+	return authn.Anonymous, nil
+}
+
+// readArtifactFiles calls fn for each maven artifact file in a rebuilt artifact image. The path is relative to the
+// root of the maven repository. Checksum files are skipped as the deployers generate their own.
+func readArtifactFiles(ctx context.Context, image string, keychain authn.Keychain, fn func(file string, size int64, content io.Reader) error) error {
+	ref, err := imagename.ParseReference(image)
+	if err != nil {
+		return err
+	}
+	img, err := remote.Image(ref, remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain))
+	if err != nil {
+		return err
+	}
+	layers := mutate.Extract(img)
+	defer layers.Close()
+	tr := tar.NewReader(layers)
+	found := false
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		file := strings.TrimPrefix(path.Clean("/"+header.Name), "/")
+		if !strings.HasPrefix(file, artifactsDir) {
+			continue
+		}
+		file = strings.TrimPrefix(file, artifactsDir)
+		if !isMavenArtifactFile(file) {
+			continue
+		}
+		found = true
+		if err := fn(file, header.Size, tr); err != nil {
+			return err
+		}
+	}
+	if !found {
+		return fmt.Errorf("image %s does not contain any artifacts", image)
+	}
+	return nil
+}
+
+// isMavenArtifactFile returns true if the path is an artifact in a maven repository layout, i.e.
+// group/path/artifact/version/artifact-version[-classifier].type
+func isMavenArtifactFile(file string) bool {
+	parts := strings.Split(file, "/")
+	if len(parts) < 4 {
+		return false
+	}
+	version := parts[len(parts)-2]
+	artifact := parts[len(parts)-3]
+	fileName := parts[len(parts)-1]
+	if !strings.HasPrefix(fileName, artifact+"-"+version) {
+		return false
+	}
+	for _, checksum := range []string{".md5", ".sha1", ".sha256", ".sha512"} {
+		if strings.HasSuffix(fileName, checksum) {
+			return false
+		}
+	}
+	return true
+}
+
+// checksums calculates the checksums that are deployed next to each artifact
+type checksums struct {
+	sha1 hash.Hash
+	md5  hash.Hash
+}
+
+func newChecksums() *checksums {
+	return &checksums{sha1: sha1.New(), md5: md5.New()}
+}
+
+func (c *checksums) Write(p []byte) (int, error) {
+	c.sha1.Write(p)
+	return c.md5.Write(p)
+}
+
+// files returns the checksum file extensions and their contents
+func (c *checksums) files() map[string]string {
+	return map[string]string{
+		".sha1": hex.EncodeToString(c.sha1.Sum(nil)),
+		".md5":  hex.EncodeToString(c.md5.Sum(nil)),
+	}
+}
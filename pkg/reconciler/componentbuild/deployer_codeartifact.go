@@ -0,0 +1,100 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// BatchDeployTaskLabel is set to the name of the ComponentBuild on batched deploy TaskRuns, they also have the
+	// DeployTaskLabel so that they are cached and pruned along with the other deploy TaskRuns
+	BatchDeployTaskLabel = "apheleia.io/batch-deploy-task"
+	// BatchArtifactsAnnotation lists the artifacts that are deployed by a batched deploy TaskRun
+	BatchArtifactsAnnotation = "apheleia.io/batch-artifacts"
+	// DeployedResult is the result of the deploy task that lists the RebuiltArtifacts it deployed, comma separated
+	DeployedResult = "DEPLOYED"
+)
+
+// codeArtifactDeployer deploys to AWS CodeArtifact by running the deploy ClusterTask, the result is handled
+// by handleTaskRunReceived. It supports deploying all the artifacts of a ComponentBuild in one batch.
+type codeArtifactDeployer struct {
+	client client.Client
+	scheme *runtime.Scheme
+	target *v1alpha2.DeploymentTarget
+	// task is the name of the ClusterTask that deploys the artifacts
+	task string
+}
+
+func (d *codeArtifactDeployer) Deploy(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild, ra *jvmbs.RebuiltArtifact, record *v1alpha2.DeploymentRecord) (bool, error) {
+	existing := v1beta1.TaskRunList{}
+	listOpts := &client.ListOptions{
+		Namespace:     abr.Namespace,
+		LabelSelector: labels.SelectorFromSet(map[string]string{DeployTaskLabel: deployTaskLabelValue(record.Name)}),
+	}
+	err := d.client.List(ctx, &existing, listOpts)
+	if err != nil {
+		return false, err
+	}
+	for _, i := range existing.Items {
+		if i.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+			return false, nil
+		}
+	}
+	tr := d.taskRun(abr, abr.Name+"-deploy-task", abr.Name)
+	tr.Labels = map[string]string{DeployTaskLabel: deployTaskLabelValue(record.Name)}
+	tr.Annotations = map[string]string{DeploymentRecordAnnotation: record.Name}
+	orerr := controllerutil.SetOwnerReference(abr, tr, d.scheme)
+	if orerr != nil {
+		log.Error(orerr, fmt.Sprintf("Error handling taskrun %s", tr.Name))
+	}
+	return false, d.client.Create(ctx, tr)
+}
+
+// DeployBatch runs a single deploy TaskRun for all the given artifacts, the TaskRun is owned by the
+// ComponentBuild and the artifacts it deployed are handled by handleBatchTaskRunReceived
+func (d *codeArtifactDeployer) DeployBatch(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, artifacts []string) error {
+	tr := d.taskRun(cb, cb.Name+"-batch-deploy-task", strings.Join(artifacts, ","))
+	tr.Labels = map[string]string{DeployTaskLabel: cb.Name, BatchDeployTaskLabel: cb.Name}
+	tr.Annotations = map[string]string{
+		BatchArtifactsAnnotation:   strings.Join(artifacts, ","),
+		DeploymentTargetAnnotation: d.target.Name,
+	}
+	orerr := controllerutil.SetOwnerReference(cb, tr, d.scheme)
+	if orerr != nil {
+		log.Error(orerr, fmt.Sprintf("Error handling taskrun %s", tr.Name))
+	}
+	log.Info("Deploying artifacts in a batch", "name", cb.Name, "artifacts", len(artifacts))
+	return d.client.Create(ctx, tr)
+}
+
+// taskRun returns a TaskRun that deploys the artifacts to the target. Only artifacts that are not deployed to the
+// target are passed, so the deploy is forced, as the deployed annotation that the task leaves on a RebuiltArtifact
+// does not say which target it was deployed to.
+func (d *codeArtifactDeployer) taskRun(owner client.Object, generateName string, artifact string) *v1beta1.TaskRun {
+	tr := &v1beta1.TaskRun{}
+	tr.GenerateName = generateName
+	tr.Namespace = owner.GetNamespace()
+	tr.Spec.TaskRef = &v1beta1.TaskRef{Name: d.task, Kind: v1beta1.ClusterTaskKind}
+	tr.Spec.Params = []v1beta1.Param{
+		{Name: "DOMAIN", Value: v1beta1.ArrayOrString{StringVal: d.target.Spec.CodeArtifact.Domain, Type: v1beta1.ParamTypeString}},
+		{Name: "OWNER", Value: v1beta1.ArrayOrString{StringVal: d.target.Spec.CodeArtifact.Owner, Type: v1beta1.ParamTypeString}},
+		{Name: "REPO", Value: v1beta1.ArrayOrString{StringVal: d.target.Spec.RepositoryURL, Type: v1beta1.ParamTypeString}},
+		{Name: "FORCE", Value: v1beta1.ArrayOrString{StringVal: "true", Type: v1beta1.ParamTypeString}},
+		{Name: "TARGET", Value: v1beta1.ArrayOrString{StringVal: d.target.Name, Type: v1beta1.ParamTypeString}},
+		{Name: "ARTIFACT", Value: v1beta1.ArrayOrString{StringVal: artifact, Type: v1beta1.ParamTypeString}},
+		{Name: "CREDENTIALS_SECRET", Value: v1beta1.ArrayOrString{StringVal: d.target.Spec.GetCredentialsSecret(), Type: v1beta1.ParamTypeString}},
+		{Name: "IMAGE_SECRET", Value: v1beta1.ArrayOrString{StringVal: d.target.Spec.GetImageSecret(), Type: v1beta1.ParamTypeString}},
+	}
+	return tr
+}
@@ -0,0 +1,67 @@
+package componentbuild
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+)
+
+// filesystemDeployer writes artifacts to a maven repository layout in a directory, this is used to build mirrors for
+// air-gapped environments from a volume mounted into the operator
+type filesystemDeployer struct {
+	root   string
+	images authn.Keychain
+	// saveRecord saves the checksums of the files written so far
+	saveRecord func(ctx context.Context, record *v1alpha2.DeploymentRecord) error
+}
+
+func (d *filesystemDeployer) Deploy(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild, ra *jvmbs.RebuiltArtifact, record *v1alpha2.DeploymentRecord) (bool, error) {
+	err := readArtifactFiles(ctx, ra.Spec.Image, d.images, func(file string, size int64, content io.Reader) error {
+		if deployedFile(record, file) {
+			return nil
+		}
+		dest := filepath.Join(d.root, filepath.FromSlash(file))
+		log.Info("Writing artifact file", "file", dest)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return err
+		}
+		sums := newChecksums()
+		if err := writeFile(dest, io.TeeReader(content, sums)); err != nil {
+			return err
+		}
+		for ext, sum := range sums.files() {
+			if err := os.WriteFile(dest+ext, []byte(sum), 0644); err != nil {
+				return err
+			}
+		}
+		recordChecksum(record, file, sums.files()[".sha1"])
+		return d.saveRecord(ctx, record)
+	})
+	return err == nil, err
+}
+
+// writeFile writes to a temporary file that is renamed once complete, so readers never see a partial artifact
+func writeFile(dest string, content io.Reader) error {
+	tmp, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), dest)
+}
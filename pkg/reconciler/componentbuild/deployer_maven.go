@@ -0,0 +1,72 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+)
+
+// mavenRequestTimeout limits how long a single file can take to upload, so that a repository that stops responding
+// does not hold up the reconcile
+const mavenRequestTimeout = 2 * time.Minute
+
+// mavenDeployer deploys to a maven repository that accepts HTTP PUT requests, such as Nexus or Artifactory. The
+// repository is expected to maintain maven-metadata.xml itself.
+type mavenDeployer struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+	images   authn.Keychain
+	// saveRecord saves the checksums of the files deployed so far
+	saveRecord func(ctx context.Context, record *v1alpha2.DeploymentRecord) error
+}
+
+func (d *mavenDeployer) Deploy(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild, ra *jvmbs.RebuiltArtifact, record *v1alpha2.DeploymentRecord) (bool, error) {
+	err := readArtifactFiles(ctx, ra.Spec.Image, d.images, func(file string, size int64, content io.Reader) error {
+		if deployedFile(record, file) {
+			return nil
+		}
+		log.Info("Deploying artifact file", "file", file, "repository", d.url)
+		sums := newChecksums()
+		if err := d.put(ctx, file, size, io.TeeReader(content, sums)); err != nil {
+			return err
+		}
+		for ext, sum := range sums.files() {
+			if err := d.put(ctx, file+ext, int64(len(sum)), strings.NewReader(sum)); err != nil {
+				return err
+			}
+		}
+		recordChecksum(record, file, sums.files()[".sha1"])
+		return d.saveRecord(ctx, record)
+	})
+	return err == nil, err
+}
+
+func (d *mavenDeployer) put(ctx context.Context, file string, size int64, body io.Reader) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, strings.TrimSuffix(d.url, "/")+"/"+file, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if d.username != "" || d.password != "" {
+		req.SetBasicAuth(d.username, d.password)
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("deploying %s to %s failed with status %s", file, d.url, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package componentbuild
+
+import (
+	"context"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"github.com/google/go-containerregistry/pkg/authn"
+	imagename "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+)
+
+// ociDeployer copies the rebuilt artifact image to another registry, tagged with the name of the RebuiltArtifact
+type ociDeployer struct {
+	repository string
+	images     authn.Keychain
+	push       authn.Keychain
+}
+
+func (d *ociDeployer) Deploy(ctx context.Context, log logr.Logger, abr *jvmbs.ArtifactBuild, ra *jvmbs.RebuiltArtifact, record *v1alpha2.DeploymentRecord) (bool, error) {
+	src, err := imagename.ParseReference(ra.Spec.Image)
+	if err != nil {
+		return false, err
+	}
+	dst, err := imagename.NewTag(d.repository + ":" + ra.Name)
+	if err != nil {
+		return false, err
+	}
+	img, err := remote.Image(src, remote.WithContext(ctx), remote.WithAuthFromKeychain(d.images))
+	if err != nil {
+		return false, err
+	}
+	log.Info("Copying artifact image", "source", src.String(), "destination", dst.String())
+	err = remote.Write(dst, img, remote.WithContext(ctx), remote.WithAuthFromKeychain(d.push))
+	if err != nil {
+		return false, err
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return false, err
+	}
+	recordChecksum(record, dst.String(), digest.String())
+	return true, nil
+}
@@ -0,0 +1,301 @@
+package componentbuild
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/google/go-containerregistry/pkg/authn"
+	imagename "github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var artifactFiles = map[string]string{
+	"artifacts/com/test/test/1.0/test-1.0.pom":         "<project/>",
+	"artifacts/com/test/test/1.0/test-1.0.jar":         "jar",
+	"artifacts/com/test/test/1.0/test-1.0-sources.jar": "sources",
+	"artifacts/com/test/test/1.0/test-1.0.jar.sha1":    "ignored",
+	"artifacts/com/test/test/maven-metadata.xml":       "ignored",
+	"logs/build.log": "ignored",
+}
+
+// pushArtifactImage pushes an image with the same layout as a rebuilt artifact image to a local registry
+func pushArtifactImage(g *WithT) (string, *httptest.Server) {
+	server := httptest.NewServer(registry.New())
+	buf := bytes.Buffer{}
+	tw := tar.NewWriter(&buf)
+	for file, content := range artifactFiles {
+		g.Expect(tw.WriteHeader(&tar.Header{Name: file, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte(content))
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	g.Expect(tw.Close()).To(Succeed())
+	layer, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(buf.Bytes())), nil
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	img, err := mutate.AppendLayers(empty.Image, layer)
+	g.Expect(err).NotTo(HaveOccurred())
+	image := strings.TrimPrefix(server.URL, "http://") + "/test/artifact-deployments:abc123"
+	ref, err := imagename.ParseReference(image)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(remote.Write(ref, img)).To(Succeed())
+	return image, server
+}
+
+// mavenRepository is a stand-in for a maven repository that records the files that are PUT
+type mavenRepository struct {
+	lock  sync.Mutex
+	files map[string]string
+	// fail is a file that the repository refuses
+	fail string
+}
+
+func (m *mavenRepository) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, ok := r.BasicAuth()
+	if r.Method != http.MethodPut || !ok || user != "deployer" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if strings.TrimPrefix(r.URL.Path, "/repo/") == m.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	body, _ := io.ReadAll(r.Body)
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.files[strings.TrimPrefix(r.URL.Path, "/repo/")] = string(body)
+	w.WriteHeader(http.StatusCreated)
+}
+
+func sha1Hex(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestMavenDeployer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	image, registryServer := pushArtifactImage(g)
+	defer registryServer.Close()
+	repo := &mavenRepository{files: map[string]string{}}
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	saved := 0
+	saveRecord := func(ctx context.Context, record *v1alpha2.DeploymentRecord) error {
+		saved++
+		return nil
+	}
+	deployer := &mavenDeployer{client: server.Client(), url: server.URL + "/repo/", username: "deployer", password: "secret", images: &dockerConfigKeychain{}, saveRecord: saveRecord}
+	ra := &jbs.RebuiltArtifact{Spec: jbs.RebuiltArtifactSpec{Image: image}}
+	record := &v1alpha2.DeploymentRecord{}
+	deployed, err := deployer.Deploy(context.TODO(), ctrl.Log, nil, ra, record)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deployed).To(BeTrue())
+	g.Expect(record.Status.Checksums).To(HaveKeyWithValue("com/test/test/1.0/test-1.0.jar", sha1Hex("jar")))
+	g.Expect(saved).To(Equal(3))
+	g.Expect(repo.files).To(HaveLen(9))
+	g.Expect(repo.files).To(HaveKeyWithValue("com/test/test/1.0/test-1.0.jar", "jar"))
+	g.Expect(repo.files).To(HaveKeyWithValue("com/test/test/1.0/test-1.0.jar.sha1", sha1Hex("jar")))
+	g.Expect(repo.files).To(HaveKey("com/test/test/1.0/test-1.0-sources.jar.md5"))
+	g.Expect(repo.files).NotTo(HaveKey("com/test/test/maven-metadata.xml"))
+
+	deployer.password = "wrong"
+	_, err = deployer.Deploy(context.TODO(), ctrl.Log, nil, ra, &v1alpha2.DeploymentRecord{})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("401"))
+}
+
+func TestMavenDeployerResume(t *testing.T) {
+	g := NewGomegaWithT(t)
+	image, registryServer := pushArtifactImage(g)
+	defer registryServer.Close()
+	repo := &mavenRepository{files: map[string]string{}, fail: "com/test/test/1.0/test-1.0.pom"}
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	var saved []string
+	saveRecord := func(ctx context.Context, record *v1alpha2.DeploymentRecord) error {
+		saved = saved[:0]
+		for file := range record.Status.Checksums {
+			saved = append(saved, file)
+		}
+		return nil
+	}
+	deployer := &mavenDeployer{client: server.Client(), url: server.URL + "/repo/", username: "deployer", password: "secret", images: &dockerConfigKeychain{}, saveRecord: saveRecord}
+	ra := &jbs.RebuiltArtifact{Spec: jbs.RebuiltArtifactSpec{Image: image}}
+	record := &v1alpha2.DeploymentRecord{}
+
+	//the files deployed before the failure are saved in the record
+	_, err := deployer.Deploy(context.TODO(), ctrl.Log, nil, ra, record)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(record.Status.Checksums).NotTo(HaveKey("com/test/test/1.0/test-1.0.pom"))
+	g.Expect(saved).To(HaveLen(len(record.Status.Checksums)))
+	done := map[string]bool{}
+	for file := range record.Status.Checksums {
+		done[file] = true
+	}
+
+	//the next attempt only deploys the files that are left
+	repo.fail = ""
+	repo.files = map[string]string{}
+	deployed, err := deployer.Deploy(context.TODO(), ctrl.Log, nil, ra, record)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deployed).To(BeTrue())
+	g.Expect(record.Status.Checksums).To(HaveLen(3))
+	g.Expect(repo.files).To(HaveLen(3 * (3 - len(done))))
+	for file := range done {
+		g.Expect(repo.files).NotTo(HaveKey(file))
+	}
+	g.Expect(repo.files).To(HaveKeyWithValue("com/test/test/1.0/test-1.0.pom", "<project/>"))
+}
+
+func TestFilesystemDeployer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	image, registryServer := pushArtifactImage(g)
+	defer registryServer.Close()
+	root := t.TempDir()
+
+	saveRecord := func(ctx context.Context, record *v1alpha2.DeploymentRecord) error {
+		return nil
+	}
+	deployer := &filesystemDeployer{root: filepath.Join(root, "mirror"), images: &dockerConfigKeychain{}, saveRecord: saveRecord}
+	ra := &jbs.RebuiltArtifact{Spec: jbs.RebuiltArtifactSpec{Image: image}}
+	deployed, err := deployer.Deploy(context.TODO(), ctrl.Log, nil, ra, &v1alpha2.DeploymentRecord{})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deployed).To(BeTrue())
+	content, err := os.ReadFile(filepath.Join(root, "mirror", "com", "test", "test", "1.0", "test-1.0.pom"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("<project/>"))
+	content, err = os.ReadFile(filepath.Join(root, "mirror", "com", "test", "test", "1.0", "test-1.0.jar.sha1"))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal(sha1Hex("jar")))
+	_, err = os.Stat(filepath.Join(root, "mirror", "logs"))
+	g.Expect(os.IsNotExist(err)).To(BeTrue())
+}
+
+func TestOCIDeployer(t *testing.T) {
+	g := NewGomegaWithT(t)
+	image, registryServer := pushArtifactImage(g)
+	defer registryServer.Close()
+	repository := strings.TrimPrefix(registryServer.URL, "http://") + "/mirror/artifacts"
+
+	deployer := &ociDeployer{repository: repository, images: &dockerConfigKeychain{}, push: &dockerConfigKeychain{}}
+	ra := &jbs.RebuiltArtifact{ObjectMeta: metav1.ObjectMeta{Name: "com.test.test.1.0-abcde"}, Spec: jbs.RebuiltArtifactSpec{Image: image}}
+	record := &v1alpha2.DeploymentRecord{}
+	deployed, err := deployer.Deploy(context.TODO(), ctrl.Log, nil, ra, record)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(deployed).To(BeTrue())
+	srcRef, err := imagename.ParseReference(image)
+	g.Expect(err).NotTo(HaveOccurred())
+	src, err := remote.Head(srcRef)
+	g.Expect(err).NotTo(HaveOccurred())
+	dstRef, err := imagename.ParseReference(repository + ":com.test.test.1.0-abcde")
+	g.Expect(err).NotTo(HaveOccurred())
+	dst, err := remote.Head(dstRef)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(dst.Digest).To(Equal(src.Digest))
+	g.Expect(record.Status.Checksums).To(HaveKeyWithValue(dstRef.String(), src.Digest.String()))
+}
+
+func TestDockerConfigKeychain(t *testing.T) {
+	g := NewGomegaWithT(t)
+	keychain, err := newDockerConfigKeychain([]byte(`{"auths":{"quay.io":{"auth":"dXNlcjpwYXNz"},"https://index.docker.io/v1/":{"username":"docker","password":"hub"}}}`))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	auth, err := keychain.Resolve(imagename.MustParseReference("quay.io/test/image:1.0").Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	config, err := auth.Authorization()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Username).To(Equal("user"))
+	g.Expect(config.Password).To(Equal("pass"))
+
+	auth, err = keychain.Resolve(imagename.MustParseReference("library/ubuntu").Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	config, err = auth.Authorization()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(config.Username).To(Equal("docker"))
+
+	auth, err = keychain.Resolve(imagename.MustParseReference("registry.test/image").Context())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(auth).To(Equal(authn.Anonymous))
+}
+
+func TestDeployToMavenRepository(t *testing.T) {
+	g := NewGomegaWithT(t)
+	image, registryServer := pushArtifactImage(g)
+	defer registryServer.Close()
+	repo := &mavenRepository{files: map[string]string{}}
+	server := httptest.NewServer(repo)
+	defer server.Close()
+
+	target := v1alpha2.DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: namespace},
+		Spec: v1alpha2.DeploymentTargetSpec{
+			RepositoryKind:    v1alpha2.RepositoryKindMaven,
+			RepositoryURL:     server.URL + "/repo",
+			CredentialsSecret: "nexus",
+		},
+	}
+	meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: metav1.ConditionTrue, Reason: v1alpha2.ReasonTargetValid})
+	credentials := v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "nexus", Namespace: namespace}, Data: map[string][]byte{v1alpha2.MavenUsernameKey: []byte("deployer"), v1alpha2.MavenPasswordKey: []byte("secret")}}
+	images := v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: v1alpha2.DefaultImageSecret, Namespace: namespace}, Data: map[string][]byte{v1.DockerConfigJsonKey: []byte(`{"auths":{}}`)}}
+	client, reconciler := setupClientAndReconciler(&target, &credentials, &images)
+	ctx := context.TODO()
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).To(Succeed())
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	ab := jbs.ArtifactBuild{}
+	abrName := types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(artifact)}
+	g.Expect(client.Get(ctx, abrName, &ab)).To(Succeed())
+	ab.Status.State = jbs.ArtifactBuildStateComplete
+	g.Expect(client.Status().Update(ctx, &ab)).To(Succeed())
+	db := jbs.DependencyBuild{ObjectMeta: metav1.ObjectMeta{Name: "test-db", Namespace: namespace}}
+	g.Expect(controllerutil.SetOwnerReference(&ab, &db, client.Scheme())).To(Succeed())
+	g.Expect(client.Create(ctx, &db)).To(Succeed())
+	ra := jbs.RebuiltArtifact{ObjectMeta: metav1.ObjectMeta{Name: abrName.Name, Namespace: namespace}, Spec: jbs.RebuiltArtifactSpec{Image: image, GAV: artifact}}
+	g.Expect(controllerutil.SetOwnerReference(&db, &ra, client.Scheme())).To(Succeed())
+	g.Expect(client.Create(ctx, &ra)).To(Succeed())
+
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repo.files).To(HaveKey("com/test/test/1.0/test-1.0.jar"))
+	record := v1alpha2.DeploymentRecord{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: deploymentRecordName(ra.Name, "nexus")}, &record)).To(Succeed())
+	g.Expect(record.Spec.GAV).To(Equal(artifact))
+	g.Expect(record.Status.Deployed).To(BeTrue())
+	g.Expect(record.Status.Location).To(Equal(server.URL + "/repo"))
+	g.Expect(record.Status.Checksums).To(HaveKeyWithValue("com/test/test/1.0/test-1.0.jar", sha1Hex("jar")))
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "test-db"}, &db)).To(Succeed())
+	g.Expect(db.Annotations).NotTo(HaveKey(DeployedAnnotation))
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).To(Succeed())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateComplete))
+	g.Expect(meta.IsStatusConditionTrue(cb.Status.Conditions, v1alpha2.ConditionArtifactsDeployed)).To(BeTrue())
+}
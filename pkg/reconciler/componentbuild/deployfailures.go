@@ -0,0 +1,71 @@
+package componentbuild
+
+import (
+	"context"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/apis"
+)
+
+const (
+	// deployBackoffBase is how long to wait before retrying after the first failed deploy or event delivery, the wait
+	// doubles after every further failure
+	deployBackoffBase = 30 * time.Second
+	// deployBackoffMax is the longest wait between attempts
+	deployBackoffMax = 30 * time.Minute
+)
+
+// retryAt returns when the next deploy recorded in the status may be attempted. The failures live on the
+// DeploymentRecord rather than the ComponentBuild status as the artifacts can be shared between ComponentBuilds.
+func retryAt(status *v1alpha2.DeploymentRecordStatus) time.Time {
+	if status.Attempts == 0 || status.LastFailureTime == nil {
+		return time.Time{}
+	}
+	return status.LastFailureTime.Add(backoff(status.Attempts))
+}
+
+// backoff returns how long to wait after the given number of failed attempts
+func backoff(attempts int) time.Duration {
+	if attempts > 16 {
+		return deployBackoffMax
+	}
+	wait := deployBackoffBase << (attempts - 1)
+	if wait > deployBackoffMax {
+		return deployBackoffMax
+	}
+	return wait
+}
+
+// recordDeployFailure counts a failed deploy attempt on the DeploymentRecord. Failures at or before the last recorded
+// one are ignored, so a failed TaskRun that is reconciled several times is only counted once.
+func (r *ReconcileArtifactBuild) recordDeployFailure(ctx context.Context, record *v1alpha2.DeploymentRecord, failedAt time.Time, message string) error {
+	if !newDeployFailure(&record.Status, failedAt) {
+		return nil
+	}
+	failed := metav1.NewTime(failedAt)
+	record.Status.Attempts++
+	record.Status.LastFailure = message
+	record.Status.LastFailureTime = &failed
+	return r.client.Status().Update(ctx, record)
+}
+
+// newDeployFailure returns true if a failure at the given time has not been recorded yet
+func newDeployFailure(status *v1alpha2.DeploymentRecordStatus, failedAt time.Time) bool {
+	return status.LastFailureTime == nil || failedAt.After(status.LastFailureTime.Time)
+}
+
+// taskRunFailure returns the time and reason a failed TaskRun failed
+func taskRunFailure(tr *v1beta1.TaskRun) (time.Time, string) {
+	message := "deploy task failed"
+	condition := tr.Status.GetCondition(apis.ConditionSucceeded)
+	if condition != nil && condition.Message != "" {
+		message = condition.Message
+		if condition.Reason != "" {
+			message = condition.Reason + ": " + condition.Message
+		}
+	}
+	return tr.Status.CompletionTime.Time, message
+}
@@ -0,0 +1,122 @@
+package componentbuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// DeploymentTargetAnnotation is set to the name of the DeploymentTarget on batched deploy TaskRuns, so the
+	// DeploymentRecords of the artifacts can be found when the TaskRun completes
+	DeploymentTargetAnnotation = "apheleia.io/deployment-target"
+	// DeploymentRecordAnnotation is set to the name of the DeploymentRecord on deploy TaskRuns, as the name can be too
+	// long for a label the DeployTaskLabel only holds a hash of it
+	DeploymentRecordAnnotation = "apheleia.io/deployment-record"
+	// maxRecordNameLength is the longest name a DeploymentRecord can have
+	maxRecordNameLength = 253
+)
+
+// deploymentRecordName returns the name of the DeploymentRecord for a RebuiltArtifact and a DeploymentTarget. Names
+// that would be too long are shortened and made unique with a hash.
+func deploymentRecordName(artifact string, target string) string {
+	name := artifact + "." + target
+	if len(name) <= maxRecordNameLength {
+		return name
+	}
+	sum := sha256.Sum256([]byte(name))
+	hash := hex.EncodeToString(sum[:])[:16]
+	return name[:maxRecordNameLength-len(hash)-1] + "-" + hash
+}
+
+// deployTaskLabelValue returns the value of the DeployTaskLabel for the deploy TaskRuns of a DeploymentRecord, a hash
+// of the record name that always fits in a label
+func deployTaskLabelValue(record string) string {
+	sum := sha256.Sum256([]byte(record))
+	return hex.EncodeToString(sum[:])[:32]
+}
+
+// deployTaskRecord returns the name of the DeploymentRecord a deploy TaskRun deploys. TaskRuns created before the
+// name was moved to an annotation still hold it in the label.
+func deployTaskRecord(tr *v1beta1.TaskRun) string {
+	if name := tr.Annotations[DeploymentRecordAnnotation]; name != "" {
+		return name
+	}
+	return tr.Labels[DeployTaskLabel]
+}
+
+// getDeploymentRecord returns the named DeploymentRecord, or nil if it does not exist
+func (r *ReconcileArtifactBuild) getDeploymentRecord(ctx context.Context, namespace string, name string) (*v1alpha2.DeploymentRecord, error) {
+	record := v1alpha2.DeploymentRecord{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &record)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// ensureDeploymentRecord returns the DeploymentRecord of the RebuiltArtifact for the target, creating it if it does not
+// exist yet. The record is owned by the RebuiltArtifact, so it is removed along with it.
+func (r *ReconcileArtifactBuild) ensureDeploymentRecord(ctx context.Context, ra *jvmbs.RebuiltArtifact, target string) (*v1alpha2.DeploymentRecord, error) {
+	name := deploymentRecordName(ra.Name, target)
+	record, err := r.getDeploymentRecord(ctx, ra.Namespace, name)
+	if err != nil || record != nil {
+		return record, err
+	}
+	record = &v1alpha2.DeploymentRecord{}
+	record.Name = name
+	record.Namespace = ra.Namespace
+	record.Spec = v1alpha2.DeploymentRecordSpec{RebuiltArtifact: ra.Name, GAV: ra.Spec.GAV, Target: target}
+	if err := controllerutil.SetOwnerReference(ra, record, r.scheme); err != nil {
+		return nil, err
+	}
+	err = r.client.Create(ctx, record)
+	if errors.IsAlreadyExists(err) {
+		return r.getDeploymentRecord(ctx, ra.Namespace, name)
+	}
+	return record, err
+}
+
+// markDeployed records that the artifact has been deployed to the location, taskRun is empty if the operator deployed
+// it itself
+func (r *ReconcileArtifactBuild) markDeployed(ctx context.Context, record *v1alpha2.DeploymentRecord, location string, taskRun string) error {
+	now := metav1.NewTime(time.Now())
+	record.Status.Deployed = true
+	record.Status.DeployedTime = &now
+	record.Status.Location = location
+	record.Status.TaskRun = taskRun
+	return r.client.Status().Update(ctx, record)
+}
+
+// deployedFile returns true if an earlier attempt to deploy the record already deployed the file, which is the case
+// once its checksum has been recorded
+func deployedFile(record *v1alpha2.DeploymentRecord, file string) bool {
+	_, ok := record.Status.Checksums[file]
+	return ok
+}
+
+// saveRecord saves the status of the record, deployers that deploy the files themselves save it after every file so
+// that a deploy that is interrupted resumes after the files that were already deployed
+func (r *ReconcileArtifactBuild) saveRecord(ctx context.Context, record *v1alpha2.DeploymentRecord) error {
+	return r.client.Status().Update(ctx, record)
+}
+
+// recordChecksum adds the checksum of a deployed file to the record
+func recordChecksum(record *v1alpha2.DeploymentRecord, file string, sum string) {
+	if record.Status.Checksums == nil {
+		record.Status.Checksums = map[string]string{}
+	}
+	record.Status.Checksums[file] = sum
+}
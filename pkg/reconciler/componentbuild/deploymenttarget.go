@@ -0,0 +1,87 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const NoConfigMessage = "No deployment target found, please create a DeploymentTarget, or an apheleia-config config map with the following keys: maven-repo, aws-owner, aws-domain"
+
+// LegacyConfigMessage is added to the ConfigValid condition of ComponentBuilds in namespaces that use the apheleia-config
+// config map, as the namespace settings are only read from a DeploymentTarget and the defaults apply
+const LegacyConfigMessage = "the apheleia-config config map only configures the repository, create a DeploymentTarget to configure retention, notification, CloudEvents and retriggering"
+
+// resolveDeploymentTarget finds the target that artifacts in the namespace are deployed to. If the namespace has no
+// DeploymentTarget the legacy apheleia-config config map is used instead. If no usable target can be found the target
+// is nil and the reason and message explain why.
+func (r *ReconcileArtifactBuild) resolveDeploymentTarget(ctx context.Context, namespace string) (target *v1alpha2.DeploymentTarget, reason string, message string, err error) {
+	targets := v1alpha2.DeploymentTargetList{}
+	err = r.client.List(ctx, &targets, client.InNamespace(namespace))
+	if err != nil {
+		return nil, "", "", err
+	}
+	switch len(targets.Items) {
+	case 0:
+		return r.legacyDeploymentTarget(ctx, namespace)
+	case 1:
+		target = &targets.Items[0]
+		usable := meta.FindStatusCondition(target.Status.Conditions, v1alpha2.ConditionUsable)
+		if usable == nil || usable.ObservedGeneration != target.Generation {
+			return nil, v1alpha2.ReasonTargetNotUsable, fmt.Sprintf("DeploymentTarget %s has not been checked yet", target.Name), nil
+		}
+		if usable.Status != metav1.ConditionTrue {
+			return nil, v1alpha2.ReasonTargetNotUsable, fmt.Sprintf("DeploymentTarget %s is not usable: %s", target.Name, usable.Message), nil
+		}
+		return target, v1alpha2.ReasonTargetFound, "", nil
+	default:
+		return nil, v1alpha2.ReasonAmbiguousTarget, fmt.Sprintf("Found %d DeploymentTargets, there must be exactly one per namespace", len(targets.Items)), nil
+	}
+}
+
+// legacyDeploymentTarget builds a CodeArtifact target from the apheleia-config config map, this will be removed once
+// all namespaces have been migrated to DeploymentTarget. Other kinds of repository need a DeploymentTarget.
+func (r *ReconcileArtifactBuild) legacyDeploymentTarget(ctx context.Context, namespace string) (*v1alpha2.DeploymentTarget, string, string, error) {
+	cm := v1.ConfigMap{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ApheleiaConfig}, &cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, v1alpha2.ReasonConfigMissing, NoConfigMessage, nil
+		}
+		return nil, "", "", err
+	}
+	if len(cm.Data[MavenRepo]) == 0 || len(cm.Data[AWSOwner]) == 0 || len(cm.Data[AWSDomain]) == 0 {
+		return nil, v1alpha2.ReasonConfigMissing, NoConfigMessage, nil
+	}
+	target := v1alpha2.DeploymentTarget{}
+	target.Name = ApheleiaConfig
+	target.Namespace = namespace
+	target.Spec = v1alpha2.DeploymentTargetSpec{
+		RepositoryURL:  cm.Data[MavenRepo],
+		RepositoryKind: v1alpha2.RepositoryKindCodeArtifact,
+		CodeArtifact:   &v1alpha2.CodeArtifactSettings{Domain: cm.Data[AWSDomain], Owner: cm.Data[AWSOwner]},
+	}
+	return &target, v1alpha2.ReasonConfigFound, "", nil
+}
+
+// namespaceSettings returns the spec of the DeploymentTarget of the namespace, which also holds the settings that are
+// not about deploying, such as retention and notification. They apply even while the target is not usable. The spec
+// is empty if the namespace does not have exactly one DeploymentTarget, so the defaults apply.
+func (r *ReconcileArtifactBuild) namespaceSettings(ctx context.Context, namespace string) (*v1alpha2.DeploymentTargetSpec, error) {
+	targets := v1alpha2.DeploymentTargetList{}
+	err := r.client.List(ctx, &targets, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	if len(targets.Items) != 1 {
+		return &v1alpha2.DeploymentTargetSpec{}, nil
+	}
+	return &targets.Items[0].Spec, nil
+}
@@ -0,0 +1,370 @@
+package componentbuild
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// deployQueueRecheckInterval is how often a ComponentBuild with queued artifacts is reconciled to check for a free
+// deploy slot, in case no deploy completes that would trigger it
+const deployQueueRecheckInterval = 30 * time.Second
+
+// deployQueue is the FIFO queue of built artifacts in a namespace that are waiting to be deployed. It is rebuilt
+// from the cache on every reconcile so that it survives operator restarts. Reconciles of the same namespace only hold
+// its deploy lock while they load the queue and admit entries. The admitted deploys are claimed until the cache shows
+// them, so that two workers cannot both admit an entry into the same free slot, and the deploys themselves run after
+// the lock is released.
+//
+// Each entry in the queue takes one deploy slot. In the PerArtifact deploy mode the entries are artifacts, in the
+// Batched mode they are ComponentBuilds whose artifacts have all been built.
+type deployQueue struct {
+	limit   int
+	batched bool
+	// running holds the DeploymentRecords that have a deploy TaskRun in progress
+	running map[string]bool
+	// runningBatches is the number of batched deploy TaskRuns in progress
+	runningBatches int
+	// batchDeploying holds the artifacts that are part of a batched deploy in progress
+	batchDeploying map[string]bool
+	// started is the number of deploys started by this reconcile
+	started int
+	// waiting holds the names of the waiting entries, in the order they were built
+	waiting []string
+	// rebuilt holds the artifacts that have a RebuiltArtifact
+	rebuilt map[string]bool
+	// target is the name of the DeploymentTarget the artifacts are deployed to
+	target string
+	// records holds the DeploymentRecords in the namespace by name
+	records     map[string]*v1alpha2.DeploymentRecord
+	maxAttempts int
+	now         time.Time
+}
+
+// loadDeployQueue builds the deploy queue for the namespace of the ComponentBuild. The state of the given
+// ComponentBuild is used as is, as it has just been calculated, the other ComponentBuilds in the namespace are read
+// from the cluster.
+func (r *ReconcileArtifactBuild) loadDeployQueue(ctx context.Context, cb *v1alpha2.ComponentBuild, target *v1alpha2.DeploymentTarget) (*deployQueue, error) {
+	q := &deployQueue{
+		limit:          target.Spec.GetMaxConcurrentDeploys(),
+		batched:        target.Spec.GetDeployMode() == v1alpha2.DeployModeBatched,
+		running:        map[string]bool{},
+		batchDeploying: map[string]bool{},
+		rebuilt:        map[string]bool{},
+		target:         target.Name,
+		records:        map[string]*v1alpha2.DeploymentRecord{},
+		maxAttempts:    target.Spec.GetMaxDeployAttempts(),
+		now:            time.Now(),
+	}
+
+	records := v1alpha2.DeploymentRecordList{}
+	err := r.client.List(ctx, &records, client.InNamespace(cb.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	for i := range records.Items {
+		q.records[records.Items[i].Name] = &records.Items[i]
+	}
+
+	//started holds the records of every deploy TaskRun in the cache, so that claims of deploys that have become
+	//visible can be dropped
+	started := map[string]bool{}
+	taskRuns := v1beta1.TaskRunList{}
+	err = r.client.List(ctx, &taskRuns, client.InNamespace(cb.Namespace), client.HasLabels{DeployTaskLabel})
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range taskRuns.Items {
+		if i.Labels[BatchDeployTaskLabel] != "" {
+			continue
+		}
+		started[deployTaskRecord(&i)] = true
+		if i.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+			q.running[deployTaskRecord(&i)] = true
+		}
+	}
+	batchTaskRuns := v1beta1.TaskRunList{}
+	err = r.client.List(ctx, &batchTaskRuns, client.InNamespace(cb.Namespace), client.HasLabels{BatchDeployTaskLabel})
+	if err != nil {
+		return nil, err
+	}
+	for i := range batchTaskRuns.Items {
+		running := batchTaskRuns.Items[i].Status.GetCondition(apis.ConditionSucceeded).IsUnknown()
+		if running {
+			q.runningBatches++
+		}
+		for _, name := range batchArtifacts(&batchTaskRuns.Items[i]) {
+			started[deploymentRecordName(name, q.target)] = true
+			if running {
+				q.batchDeploying[name] = true
+			}
+		}
+	}
+
+	//deploys that workers have admitted keep their slot until they show up in the cache
+	for _, c := range r.deploys.pruneClaims(cb.Namespace, func(c *deployClaim) bool {
+		return c.finished == nil || (q.now.Sub(*c.finished) < deployClaimGrace && !c.shown(q, started))
+	}) {
+		for _, name := range c.artifacts {
+			if c.batch {
+				q.batchDeploying[name] = true
+			} else {
+				q.running[deploymentRecordName(name, c.target)] = true
+			}
+		}
+		if c.batch {
+			q.runningBatches++
+		}
+	}
+
+	rebuiltArtifacts := jvmbs.RebuiltArtifactList{}
+	err = r.client.List(ctx, &rebuiltArtifacts, client.InNamespace(cb.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	created := map[string]time.Time{}
+	for _, i := range rebuiltArtifacts.Items {
+		q.rebuilt[i.Name] = true
+		created[i.Name] = i.CreationTimestamp.Time
+	}
+
+	cbList := v1alpha2.ComponentBuildList{}
+	err = r.client.List(ctx, &cbList, client.InNamespace(cb.Namespace))
+	if err != nil {
+		return nil, err
+	}
+	builds := []*v1alpha2.ComponentBuild{cb}
+	for i := range cbList.Items {
+		if cbList.Items[i].Name != cb.Name {
+			builds = append(builds, &cbList.Items[i])
+		}
+	}
+	//entries are ordered by when they became ready to deploy, for a batch that is when its last artifact was built
+	ready := map[string]time.Time{}
+	for _, build := range builds {
+		if q.batched {
+			pending := q.pending(build)
+			if len(pending) == 0 || !allBuilt(build) {
+				continue
+			}
+			for _, name := range pending {
+				if created[name].After(ready[build.Name]) {
+					ready[build.Name] = created[name]
+				}
+			}
+			q.waiting = append(q.waiting, build.Name)
+			continue
+		}
+		for _, name := range q.pending(build) {
+			if _, ok := ready[name]; !ok {
+				ready[name] = created[name]
+				q.waiting = append(q.waiting, name)
+			}
+		}
+	}
+	sort.SliceStable(q.waiting, func(i, j int) bool {
+		a, b := ready[q.waiting[i]], ready[q.waiting[j]]
+		if a.Equal(b) {
+			return q.waiting[i] < q.waiting[j]
+		}
+		return a.Before(b)
+	})
+	return q, nil
+}
+
+// pending returns the artifacts of the ComponentBuild that have been built but are not deployed or being deployed.
+// Artifacts that are waiting to be retried after a failure, or that have failed too often, are left out so they
+// don't hold up the queue. The status of other ComponentBuilds may be stale, so the DeploymentRecord is checked as
+// well.
+func (q *deployQueue) pending(cb *v1alpha2.ComponentBuild) []string {
+	var pending []string
+	for _, state := range cb.Status.ArtifactState {
+		if !state.Built || state.Deployed {
+			continue
+		}
+		if !q.rebuilt[state.ArtifactBuild] || q.record(state.ArtifactBuild).Deployed || q.deploying(state.ArtifactBuild) || q.deployFailed(state.ArtifactBuild) || q.retryIn(state.ArtifactBuild) > 0 {
+			continue
+		}
+		pending = append(pending, state.ArtifactBuild)
+	}
+	return pending
+}
+
+// allBuilt returns true once none of the artifacts of the ComponentBuild are still being built
+func allBuilt(cb *v1alpha2.ComponentBuild) bool {
+	for _, state := range cb.Status.ArtifactState {
+		if !state.Built && !state.Failed {
+			return false
+		}
+	}
+	return true
+}
+
+// batchArtifacts returns the artifacts that are deployed by a batched deploy TaskRun
+func batchArtifacts(tr *v1beta1.TaskRun) []string {
+	if tr.Annotations[BatchArtifactsAnnotation] == "" {
+		return nil
+	}
+	return strings.Split(tr.Annotations[BatchArtifactsAnnotation], ",")
+}
+
+// record returns the status of the DeploymentRecord of the artifact for the target, which is empty if the artifact
+// has not been deployed yet
+func (q *deployQueue) record(name string) *v1alpha2.DeploymentRecordStatus {
+	if record, ok := q.records[deploymentRecordName(name, q.target)]; ok {
+		return &record.Status
+	}
+	return &v1alpha2.DeploymentRecordStatus{}
+}
+
+// deployFailed returns true if the artifact has used up all its deploy attempts
+func (q *deployQueue) deployFailed(name string) bool {
+	return q.record(name).Attempts >= q.maxAttempts
+}
+
+// retryIn returns how long until a failed deploy of the artifact may be retried, or zero if it can be deployed now
+func (q *deployQueue) retryIn(name string) time.Duration {
+	status := q.record(name)
+	if status.Attempts == 0 || q.deployFailed(name) {
+		return 0
+	}
+	wait := retryAt(status).Sub(q.now)
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// deploying returns true if the artifact has a deploy in progress
+func (q *deployQueue) deploying(name string) bool {
+	if q.batchDeploying[name] {
+		return true
+	}
+	return q.running[deploymentRecordName(name, q.target)]
+}
+
+// queued returns true if the entry is waiting for a free deploy slot
+func (q *deployQueue) queued(name string) bool {
+	for _, i := range q.waiting {
+		if i == name {
+			return true
+		}
+	}
+	return false
+}
+
+// admit returns true if the entry may be deployed now, which is the case if it is within the number of free
+// deploy slots from the front of the queue. Admitted entries leave the queue.
+func (q *deployQueue) admit(name string) bool {
+	free := q.limit - len(q.running) - q.runningBatches - q.started
+	for i := 0; i < len(q.waiting) && i < free; i++ {
+		if q.waiting[i] == name {
+			q.waiting = append(q.waiting[:i], q.waiting[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// namespaceDeploys holds a lock for each namespace that has deploys, and the deploys that workers have claimed
+type namespaceDeploys struct {
+	mutex  sync.Mutex
+	locks  map[string]*sync.Mutex
+	claims map[string][]*deployClaim
+}
+
+// deployClaim is a deploy that a worker admitted from the queue. It keeps its slot after the worker is done with it
+// until the cache shows the deploy, or for deployClaimGrace if it never does because it could not be started.
+type deployClaim struct {
+	// artifacts are the artifacts that are deployed, a batched deploy has several
+	artifacts []string
+	// target is the name of the DeploymentTarget the artifacts are deployed to
+	target string
+	batch  bool
+	// attempts holds the failed deploy attempts of each artifact when the deploy was admitted
+	attempts map[string]int
+	// finished is when the worker was done with the deploy, it is nil while the deploy runs
+	finished *time.Time
+}
+
+// deployClaimGrace is how long a finished deploy keeps its slot at most while it is not in the cache
+const deployClaimGrace = 30 * time.Second
+
+// lock locks the namespace and returns the function that unlocks it
+func (n *namespaceDeploys) lock(namespace string) func() {
+	n.mutex.Lock()
+	if n.locks == nil {
+		n.locks = map[string]*sync.Mutex{}
+	}
+	l := n.locks[namespace]
+	if l == nil {
+		l = &sync.Mutex{}
+		n.locks[namespace] = l
+	}
+	n.mutex.Unlock()
+	l.Lock()
+	return l.Unlock
+}
+
+// claim claims the deploy of the given artifacts, which were admitted from the queue. The namespace must be locked.
+func (n *namespaceDeploys) claim(q *deployQueue, namespace string, batch bool, artifacts ...string) *deployClaim {
+	c := &deployClaim{artifacts: artifacts, target: q.target, batch: batch, attempts: map[string]int{}}
+	for _, name := range artifacts {
+		c.attempts[name] = q.record(name).Attempts
+	}
+	q.started++
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	if n.claims == nil {
+		n.claims = map[string][]*deployClaim{}
+	}
+	n.claims[namespace] = append(n.claims[namespace], c)
+	return c
+}
+
+// finish records that the worker is done with the claimed deploy, the claim is kept until the cache shows the deploy
+func (n *namespaceDeploys) finish(c *deployClaim) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	now := time.Now()
+	c.finished = &now
+}
+
+// pruneClaims drops the claims of the namespace that keep returns false for, and returns the remaining claims
+func (n *namespaceDeploys) pruneClaims(namespace string, keep func(c *deployClaim) bool) []*deployClaim {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	var kept []*deployClaim
+	for _, c := range n.claims[namespace] {
+		if keep(c) {
+			kept = append(kept, c)
+		}
+	}
+	if len(kept) == 0 {
+		delete(n.claims, namespace)
+	} else {
+		n.claims[namespace] = kept
+	}
+	return kept
+}
+
+// shown returns true once the cache shows the claimed deploy, either as a deploy TaskRun or as a result in the
+// DeploymentRecords of all its artifacts
+func (c *deployClaim) shown(q *deployQueue, started map[string]bool) bool {
+	for _, name := range c.artifacts {
+		record := q.records[deploymentRecordName(name, c.target)]
+		if started[deploymentRecordName(name, c.target)] || (record != nil && (record.Status.Deployed || record.Status.Attempts > c.attempts[name])) {
+			continue
+		}
+		return false
+	}
+	return true
+}
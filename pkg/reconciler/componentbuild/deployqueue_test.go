@@ -0,0 +1,423 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// createBuiltArtifact creates a completed ArtifactBuild with the DependencyBuild and RebuiltArtifact that a
+// successful build leaves behind, the RebuiltArtifact is created at the given time
+func createBuiltArtifact(g *WithT, client runtimeclient.Client, gav string, created time.Time) string {
+	ctx := context.TODO()
+	abr := jbs.ArtifactBuild{}
+	abr.Namespace = namespace
+	abr.Name = artifactbuild.CreateABRName(gav)
+	abr.Spec.GAV = gav
+	g.Expect(client.Create(ctx, &abr)).NotTo(HaveOccurred())
+	abr.Status.State = jbs.ArtifactBuildStateComplete
+	g.Expect(client.Status().Update(ctx, &abr)).NotTo(HaveOccurred())
+
+	db := jbs.DependencyBuild{}
+	db.Namespace = namespace
+	db.Name = abr.Name + "-db"
+	g.Expect(controllerutil.SetOwnerReference(&abr, &db, client.Scheme())).NotTo(HaveOccurred())
+	g.Expect(client.Create(ctx, &db)).NotTo(HaveOccurred())
+
+	ra := jbs.RebuiltArtifact{}
+	ra.Namespace = namespace
+	ra.Name = abr.Name
+	ra.CreationTimestamp = metav1.Time{Time: created}
+	ra.Spec.Image = TestImage
+	ra.Spec.GAV = gav
+	g.Expect(controllerutil.SetOwnerReference(&db, &ra, client.Scheme())).NotTo(HaveOccurred())
+	g.Expect(client.Create(ctx, &ra)).NotTo(HaveOccurred())
+	return abr.Name
+}
+
+func TestDeployQueue(t *testing.T) {
+	g := NewGomegaWithT(t)
+	target := v1alpha2.DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace, Generation: 1},
+		Spec: v1alpha2.DeploymentTargetSpec{
+			RepositoryURL:        DummyRepo,
+			CodeArtifact:         &v1alpha2.CodeArtifactSettings{Domain: DummyDomain, Owner: DummyOwner},
+			MaxConcurrentDeploys: 2,
+		},
+	}
+	meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: metav1.ConditionTrue, Reason: v1alpha2.ReasonTargetValid, ObservedGeneration: 1})
+	client, reconciler := setupClientAndReconciler(&target)
+	ctx := context.TODO()
+
+	//the artifacts are built in the reverse order of the spec, so the last one is deployed first
+	gavs := []string{"com.test:first:1.0", "com.test:second:1.0", "com.test:third:1.0"}
+	now := time.Now()
+	var names []string
+	for i, gav := range gavs {
+		names = append(names, createBuiltArtifact(g, client, gav, now.Add(-time.Duration(i)*time.Minute)))
+	}
+	cb := defaultComponentBuild()
+	cb.Spec.Artifacts = nil
+	for _, gav := range gavs {
+		cb.Spec.Artifacts = append(cb.Spec.Artifacts, v1alpha2.ParseGAV(gav))
+	}
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(Equal(deployQueueRecheckInterval))
+
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(2))
+	for _, tr := range trl.Items {
+		g.Expect(tr.Annotations[DeploymentRecordAnnotation]).NotTo(Equal(deploymentRecordName(names[0], "test")))
+	}
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Deploying).To(Equal(2))
+	g.Expect(cb.Status.Queued).To(Equal(1))
+	g.Expect(cb.Status.GetArtifactState(gavs[0]).Queued).To(BeTrue())
+	g.Expect(cb.Status.GetArtifactState(gavs[2]).Deploying).To(BeTrue())
+	g.Expect(meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionArtifactsDeployed).Reason).To(Equal(v1alpha2.ReasonDeployQueued))
+
+	//reconciling again while both deploys are running does not start another one
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(2))
+
+	//finishing a deploy frees a slot for the queued artifact
+	tr := trl.Items[0]
+	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	tr.Status.SetCondition(&apis.Condition{
+		Type:               apis.ConditionSucceeded,
+		Status:             "True",
+		LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+	})
+	g.Expect(client.Status().Update(ctx, &tr)).NotTo(HaveOccurred())
+	g.Expect(reconcileTaskRun(ctx, client, reconciler, types.NamespacedName{Namespace: tr.Namespace, Name: tr.Name})).NotTo(HaveOccurred())
+
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(3))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Deploying).To(Equal(2))
+	g.Expect(cb.Status.Queued).To(Equal(0))
+	g.Expect(cb.Status.Outstanding).To(Equal(2))
+}
+
+func TestBatchedDeploy(t *testing.T) {
+	g := NewGomegaWithT(t)
+	target := v1alpha2.DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace, Generation: 1},
+		Spec: v1alpha2.DeploymentTargetSpec{
+			RepositoryURL: DummyRepo,
+			CodeArtifact:  &v1alpha2.CodeArtifactSettings{Domain: DummyDomain, Owner: DummyOwner},
+			DeployMode:    v1alpha2.DeployModeBatched,
+		},
+	}
+	meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: metav1.ConditionTrue, Reason: v1alpha2.ReasonTargetValid, ObservedGeneration: 1})
+	client, reconciler := setupClientAndReconciler(&target)
+	ctx := context.TODO()
+
+	gavs := []string{"com.test:first:1.0", "com.test:second:1.0"}
+	first := createBuiltArtifact(g, client, gavs[0], time.Now())
+	cb := defaultComponentBuild()
+	cb.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV(gavs[0]), v1alpha2.ParseGAV(gavs[1])}
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+
+	//nothing is deployed while the second artifact is still building
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(trl.Items).To(BeEmpty())
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Queued).To(Equal(0))
+
+	abr := jbs.ArtifactBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(gavs[1])}, &abr)).NotTo(HaveOccurred())
+	g.Expect(client.Delete(ctx, &abr)).NotTo(HaveOccurred())
+	second := createBuiltArtifact(g, client, gavs[1], time.Now())
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(1))
+	tr := trl.Items[0]
+	g.Expect(tr.Labels[BatchDeployTaskLabel]).To(Equal(name))
+	paramMap := map[string]string{}
+	for _, p := range tr.Spec.Params {
+		paramMap[p.Name] = p.Value.StringVal
+	}
+	g.Expect(paramMap["ARTIFACT"]).To(Equal(first + "," + second))
+	g.Expect(paramMap["FORCE"]).To(Equal("true"))
+	g.Expect(paramMap["TARGET"]).To(Equal("test"))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Deploying).To(Equal(2))
+
+	//the deploy task only managed to deploy the first artifact, the second was deployed to another target before
+	ra := jbs.RebuiltArtifact{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: second}, &ra)).NotTo(HaveOccurred())
+	ra.Annotations = map[string]string{DeployedAnnotation: "3"}
+	g.Expect(client.Update(ctx, &ra)).NotTo(HaveOccurred())
+	tr.Status.TaskRunResults = []v1beta1.TaskRunResult{{Name: DeployedResult, Value: *v1beta1.NewStructuredValues(first)}}
+	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	tr.Status.SetCondition(&apis.Condition{
+		Type:               apis.ConditionSucceeded,
+		Status:             "False",
+		LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: time.Now()}},
+	})
+	g.Expect(client.Status().Update(ctx, &tr)).NotTo(HaveOccurred())
+	g.Expect(reconcileTaskRun(ctx, client, reconciler, types.NamespacedName{Namespace: tr.Namespace, Name: tr.Name})).NotTo(HaveOccurred())
+
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.GetArtifactState(gavs[0]).Deployed).To(BeTrue())
+	g.Expect(cb.Status.GetArtifactState(gavs[1]).Deployed).To(BeFalse())
+	g.Expect(cb.Status.GetArtifactState(gavs[1]).DeployAttempts).To(Equal(1))
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(1))
+
+	//once the backoff has passed the artifact that was not deployed is retried in a new batch
+	expireDeployBackoff(g, client, deploymentRecordName(second, "test"))
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(2))
+	for _, i := range trl.Items {
+		if i.Name != tr.Name {
+			g.Expect(i.Annotations[BatchArtifactsAnnotation]).To(Equal(second))
+		}
+	}
+}
+
+// expireDeployBackoff moves the last deploy failure of the DeploymentRecord into the past so it can be retried
+func expireDeployBackoff(g *WithT, client runtimeclient.Client, name string) {
+	record := v1alpha2.DeploymentRecord{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: name}, &record)).NotTo(HaveOccurred())
+	failedAt := metav1.NewTime(record.Status.LastFailureTime.Add(-deployBackoffMax))
+	record.Status.LastFailureTime = &failedAt
+	g.Expect(client.Status().Update(context.TODO(), &record)).NotTo(HaveOccurred())
+}
+
+// failTaskRun completes the TaskRun with a failure at the given time
+func failTaskRun(g *WithT, client runtimeclient.Client, tr *v1beta1.TaskRun, failedAt time.Time) {
+	tr.Status.CompletionTime = &metav1.Time{Time: failedAt}
+	tr.Status.SetCondition(&apis.Condition{
+		Type:               apis.ConditionSucceeded,
+		Status:             "False",
+		Reason:             "Failed",
+		Message:            "unable to fetch token",
+		LastTransitionTime: apis.VolatileTime{Inner: metav1.Time{Time: failedAt}},
+	})
+	g.Expect(client.Status().Update(context.TODO(), tr)).NotTo(HaveOccurred())
+}
+
+func TestDeployRetry(t *testing.T) {
+	g := NewGomegaWithT(t)
+	target := v1alpha2.DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace, Generation: 1},
+		Spec: v1alpha2.DeploymentTargetSpec{
+			RepositoryURL:     DummyRepo,
+			CodeArtifact:      &v1alpha2.CodeArtifactSettings{Domain: DummyDomain, Owner: DummyOwner},
+			MaxDeployAttempts: 2,
+		},
+	}
+	meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: metav1.ConditionTrue, Reason: v1alpha2.ReasonTargetValid, ObservedGeneration: 1})
+	client, reconciler := setupClientAndReconciler(&target)
+	ctx := context.TODO()
+	abrName := createBuiltArtifact(g, client, artifact, time.Now())
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(1))
+	failTaskRun(g, client, &trl.Items[0], time.Now())
+	trName := types.NamespacedName{Namespace: namespace, Name: trl.Items[0].Name}
+	//the failure is only counted once, even if the TaskRun is reconciled again
+	failedDeploys := testutil.ToFloat64(deploys.WithLabelValues(namespace, outcomeFailed))
+	for i := 0; i < 2; i++ {
+		g.Expect(reconcileTaskRun(ctx, client, reconciler, trName)).NotTo(HaveOccurred())
+	}
+	g.Expect(testutil.ToFloat64(deploys.WithLabelValues(namespace, outcomeFailed))).To(Equal(failedDeploys + 1))
+	result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+	g.Expect(result.RequeueAfter).To(BeNumerically("<=", deployBackoffBase))
+
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	state := cb.Status.GetArtifactState(artifact)
+	g.Expect(state.DeployAttempts).To(Equal(1))
+	g.Expect(state.LastDeployFailure).To(Equal("Failed: unable to fetch token"))
+	g.Expect(state.DeployFailed).To(BeFalse())
+	g.Expect(state.Queued).To(BeFalse())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateInProgress))
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(1))
+
+	//after the backoff the deploy is retried, and failing again uses up the attempts
+	expireDeployBackoff(g, client, deploymentRecordName(abrName, "test"))
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(2))
+	for i := range trl.Items {
+		if trl.Items[i].Name != trName.Name {
+			failTaskRun(g, client, &trl.Items[i], time.Now())
+			g.Expect(reconcileTaskRun(ctx, client, reconciler, types.NamespacedName{Namespace: namespace, Name: trl.Items[i].Name})).NotTo(HaveOccurred())
+		}
+	}
+
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	state = cb.Status.GetArtifactState(artifact)
+	g.Expect(state.DeployAttempts).To(Equal(2))
+	g.Expect(state.DeployFailed).To(BeTrue())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateFailed))
+	g.Expect(meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionArtifactsDeployed).Reason).To(Equal(v1alpha2.ReasonDeployFailed))
+	expireDeployBackoff(g, client, deploymentRecordName(abrName, "test"))
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(2))
+}
+
+// slowTaskRunClient delays listing TaskRuns, so that concurrent reconciles all read the running deploys before any
+// of them starts one unless they are serialised
+type slowTaskRunClient struct {
+	runtimeclient.Client
+}
+
+func (c slowTaskRunClient) List(ctx context.Context, list runtimeclient.ObjectList, opts ...runtimeclient.ListOption) error {
+	if _, ok := list.(*v1beta1.TaskRunList); ok {
+		time.Sleep(50 * time.Millisecond)
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestConcurrentDeployAdmission(t *testing.T) {
+	g := NewGomegaWithT(t)
+	target := v1alpha2.DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace, Generation: 1},
+		Spec: v1alpha2.DeploymentTargetSpec{
+			RepositoryURL:        DummyRepo,
+			CodeArtifact:         &v1alpha2.CodeArtifactSettings{Domain: DummyDomain, Owner: DummyOwner},
+			MaxConcurrentDeploys: 1,
+		},
+	}
+	meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: metav1.ConditionTrue, Reason: v1alpha2.ReasonTargetValid, ObservedGeneration: 1})
+	client, reconciler := setupClientAndReconciler(&target)
+	reconciler.client = slowTaskRunClient{Client: client}
+	ctx := context.TODO()
+
+	//two ComponentBuilds with a built artifact each are reconciled by different workers at the same time
+	gavs := []string{"com.test:first:1.0", "com.test:second:1.0"}
+	var requests []reconcile.Request
+	for i, gav := range gavs {
+		createBuiltArtifact(g, client, gav, time.Now())
+		cb := defaultComponentBuild()
+		cb.Name = fmt.Sprintf("%s-%d", name, i)
+		cb.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV(gav)}
+		g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: cb.Name}})
+	}
+	var wg sync.WaitGroup
+	errs := make([]error, len(requests))
+	for i := range requests {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = reconciler.Reconcile(ctx, requests[i])
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	//only one deploy fits into the namespace
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(len(trl.Items)).To(Equal(1))
+}
+
+// staleTaskRunClient is a client whose cache has not seen any TaskRuns yet
+type staleTaskRunClient struct {
+	runtimeclient.Client
+}
+
+func (c staleTaskRunClient) List(ctx context.Context, list runtimeclient.ObjectList, opts ...runtimeclient.ListOption) error {
+	if _, ok := list.(*v1beta1.TaskRunList); ok {
+		return nil
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func TestDeployClaimedUntilCached(t *testing.T) {
+	g := NewGomegaWithT(t)
+	target := v1alpha2.DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: namespace, Generation: 1},
+		Spec: v1alpha2.DeploymentTargetSpec{
+			RepositoryURL:        DummyRepo,
+			CodeArtifact:         &v1alpha2.CodeArtifactSettings{Domain: DummyDomain, Owner: DummyOwner},
+			MaxConcurrentDeploys: 1,
+		},
+	}
+	meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: metav1.ConditionTrue, Reason: v1alpha2.ReasonTargetValid, ObservedGeneration: 1})
+	client, reconciler := setupClientAndReconciler(&target)
+	reconciler.client = staleTaskRunClient{Client: client}
+	ctx := context.TODO()
+
+	gavs := []string{"com.test:first:1.0", "com.test:second:1.0"}
+	var requests []reconcile.Request
+	for i, gav := range gavs {
+		createBuiltArtifact(g, client, gav, time.Now().Add(time.Duration(i)*time.Second))
+		cb := defaultComponentBuild()
+		cb.Name = fmt.Sprintf("%s-%d", name, i)
+		cb.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV(gav)}
+		g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: cb.Name}})
+	}
+	countTaskRuns := func() int {
+		trl := v1beta1.TaskRunList{}
+		g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+		return len(trl.Items)
+	}
+
+	//the first deploy holds the only slot while its TaskRun is missing from the cache, so it is not started again
+	for _, request := range []reconcile.Request{requests[0], requests[1], requests[0]} {
+		_, err := reconciler.Reconcile(ctx, request)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(countTaskRuns()).To(Equal(1))
+	}
+	cb := v1alpha2.ComponentBuild{}
+	g.Expect(client.Get(ctx, requests[1].NamespacedName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Queued).To(Equal(1))
+
+	//once the cache shows the TaskRun the claim is dropped, and the TaskRun holds the slot
+	reconciler.client = client
+	_, err := reconciler.Reconcile(ctx, requests[1])
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(countTaskRuns()).To(Equal(1))
+	g.Expect(reconciler.deploys.claims[namespace]).To(BeEmpty())
+}
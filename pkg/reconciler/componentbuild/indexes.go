@@ -0,0 +1,173 @@
+package componentbuild
+
+import (
+	"context"
+	"sort"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// ArtifactGAVIndex indexes ComponentBuilds by the GAVs in their spec
+	ArtifactGAVIndex = "spec.artifacts.gav"
+	// ArtifactBuildIndex indexes ComponentBuilds by the names of the ArtifactBuilds in their status
+	ArtifactBuildIndex = "status.artifactState.artifactBuild"
+	// DependencyBuildIndex indexes RebuiltArtifacts by the names of the DependencyBuilds that own them, it is used to
+	// migrate the deploy annotations of the DependencyBuilds
+	DependencyBuildIndex = "metadata.ownerReferences.dependencyBuild"
+)
+
+// componentBuildIndexes returns the values of each field index of a ComponentBuild
+var componentBuildIndexes = map[string]func(cb *v1alpha2.ComponentBuild) []string{
+	ArtifactGAVIndex: func(cb *v1alpha2.ComponentBuild) []string {
+		var gavs []string
+		for i := range cb.Spec.Artifacts {
+			gavs = append(gavs, cb.Spec.Artifacts[i].GAV())
+		}
+		return gavs
+	},
+	ArtifactBuildIndex: func(cb *v1alpha2.ComponentBuild) []string {
+		var names []string
+		for _, state := range cb.Status.ArtifactState {
+			if state.ArtifactBuild != "" {
+				names = append(names, state.ArtifactBuild)
+			}
+		}
+		return names
+	},
+}
+
+// setupIndexes adds the ComponentBuild and RebuiltArtifact field indexes to the cache
+func setupIndexes(ctx context.Context, indexer client.FieldIndexer) error {
+	for name, values := range componentBuildIndexes {
+		values := values
+		err := indexer.IndexField(ctx, &v1alpha2.ComponentBuild{}, name, func(o client.Object) []string {
+			return values(o.(*v1alpha2.ComponentBuild))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return indexer.IndexField(ctx, &jvmbs.RebuiltArtifact{}, DependencyBuildIndex, func(o client.Object) []string {
+		return ownerNames(o, "DependencyBuild")
+	})
+}
+
+// listComponentBuilds returns the ComponentBuilds in the namespace that have any of the values in the index, sorted by
+// name. The values are checked again after listing, so that readers without the index return the same result.
+func listComponentBuilds(ctx context.Context, c client.Reader, namespace string, index string, values ...string) ([]v1alpha2.ComponentBuild, error) {
+	found := map[string]v1alpha2.ComponentBuild{}
+	for _, value := range values {
+		cbList := v1alpha2.ComponentBuildList{}
+		err := c.List(ctx, &cbList, client.InNamespace(namespace), client.MatchingFields{index: value})
+		if err != nil {
+			return nil, err
+		}
+		for _, cb := range cbList.Items {
+			cb := cb
+			for _, v := range componentBuildIndexes[index](&cb) {
+				if v == value {
+					found[cb.Name] = cb
+					break
+				}
+			}
+		}
+	}
+	builds := make([]v1alpha2.ComponentBuild, 0, len(found))
+	for _, cb := range found {
+		builds = append(builds, cb)
+	}
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].Name < builds[j].Name
+	})
+	return builds, nil
+}
+
+// artifactBuildComponentBuilds returns the ComponentBuilds that the ArtifactBuild is rebuilding a dependency for,
+// those that list its GAV in their spec and those that have already recorded its state
+func artifactBuildComponentBuilds(ctx context.Context, c client.Reader, abr *jvmbs.ArtifactBuild) ([]v1alpha2.ComponentBuild, error) {
+	builds, err := listComponentBuilds(ctx, c, abr.Namespace, ArtifactBuildIndex, abr.Name)
+	if err != nil {
+		return nil, err
+	}
+	if abr.Spec.GAV == "" {
+		return builds, nil
+	}
+	byGAV, err := listComponentBuilds(ctx, c, abr.Namespace, ArtifactGAVIndex, abr.Spec.GAV)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{}
+	for _, cb := range builds {
+		seen[cb.Name] = true
+	}
+	for _, cb := range byGAV {
+		if !seen[cb.Name] {
+			builds = append(builds, cb)
+		}
+	}
+	sort.Slice(builds, func(i, j int) bool {
+		return builds[i].Name < builds[j].Name
+	})
+	return builds, nil
+}
+
+// requestsFor returns reconcile requests for the ComponentBuilds
+func requestsFor(builds []v1alpha2.ComponentBuild) []reconcile.Request {
+	requests := make([]reconcile.Request, 0, len(builds))
+	for _, cb := range builds {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: cb.Namespace, Name: cb.Name}})
+	}
+	return requests
+}
+
+// ownerNames returns the names of the owners of the object of the given kind
+func ownerNames(o client.Object, kind string) []string {
+	var names []string
+	for _, ownerReference := range o.GetOwnerReferences() {
+		if ownerReference.Kind == kind {
+			names = append(names, ownerReference.Name)
+		}
+	}
+	return names
+}
+
+// dependencyBuildArtifacts returns the RebuiltArtifacts owned by the DependencyBuild. As with listComponentBuilds the
+// owner is checked again after listing.
+func dependencyBuildArtifacts(ctx context.Context, c client.Reader, db *jvmbs.DependencyBuild) ([]jvmbs.RebuiltArtifact, error) {
+	raList := jvmbs.RebuiltArtifactList{}
+	err := c.List(ctx, &raList, client.InNamespace(db.Namespace), client.MatchingFields{DependencyBuildIndex: db.Name})
+	if err != nil {
+		return nil, err
+	}
+	var artifacts []jvmbs.RebuiltArtifact
+	for _, ra := range raList.Items {
+		if ownedBy(&ra, "DependencyBuild", db.Name) {
+			artifacts = append(artifacts, ra)
+		}
+	}
+	return artifacts, nil
+}
+
+// rebuiltArtifactComponentBuilds returns the ComponentBuilds that depend on the RebuiltArtifact, found through the
+// ArtifactBuild of the same name and the ArtifactBuilds that own its DependencyBuild
+func rebuiltArtifactComponentBuilds(ctx context.Context, c client.Reader, ra *jvmbs.RebuiltArtifact) ([]v1alpha2.ComponentBuild, error) {
+	names := []string{ra.Name}
+	for _, owner := range ownerNames(ra, "DependencyBuild") {
+		db := jvmbs.DependencyBuild{}
+		err := c.Get(ctx, types.NamespacedName{Namespace: ra.Namespace, Name: owner}, &db)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		names = append(names, ownerNames(&db, "ArtifactBuild")...)
+	}
+	return listComponentBuilds(ctx, c, ra.Namespace, ArtifactBuildIndex, names...)
+}
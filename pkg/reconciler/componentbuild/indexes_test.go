@@ -0,0 +1,99 @@
+package componentbuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// recordingIndexer records the index functions it is given
+type recordingIndexer map[string]client.IndexerFunc
+
+func (r recordingIndexer) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	r[field] = extractValue
+	return nil
+}
+
+func TestComponentBuildIndexes(t *testing.T) {
+	g := NewGomegaWithT(t)
+	indexer := recordingIndexer{}
+	g.Expect(setupIndexes(context.TODO(), indexer)).To(Succeed())
+	cb := defaultComponentBuild()
+	cb.Spec.Artifacts = append(cb.Spec.Artifacts, v1alpha2.ParseGAV("com.test:other:2.0"))
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, ArtifactBuild: artifactbuild.CreateABRName(artifact)}, {GAV: "com.test:other:2.0"}}
+	g.Expect(indexer[ArtifactGAVIndex](&cb)).To(Equal([]string{artifact, "com.test:other:2.0"}))
+	g.Expect(indexer[ArtifactBuildIndex](&cb)).To(Equal([]string{artifactbuild.CreateABRName(artifact)}))
+	ra := jbs.RebuiltArtifact{ObjectMeta: metav1.ObjectMeta{OwnerReferences: []metav1.OwnerReference{{Kind: "DependencyBuild", Name: "test-db"}, {Kind: "ArtifactBuild", Name: "test"}}}}
+	g.Expect(indexer[DependencyBuildIndex](&ra)).To(Equal([]string{"test-db"}))
+}
+
+func TestArtifactBuildComponentBuilds(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	abrName := artifactbuild.CreateABRName(artifact)
+	//one build has already recorded the ArtifactBuild, one has only just been created, and one is unrelated
+	recorded := defaultComponentBuild()
+	recorded.Name = "recorded"
+	recorded.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, ArtifactBuild: abrName}}
+	created := defaultComponentBuild()
+	created.Name = "created"
+	unrelated := defaultComponentBuild()
+	unrelated.Name = "unrelated"
+	unrelated.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV("com.test:other:2.0")}
+	for _, cb := range []*v1alpha2.ComponentBuild{&recorded, &created, &unrelated} {
+		g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+	}
+	abr := jbs.ArtifactBuild{ObjectMeta: metav1.ObjectMeta{Name: abrName, Namespace: namespace}, Spec: jbs.ArtifactBuildSpec{GAV: artifact}}
+
+	builds, err := artifactBuildComponentBuilds(ctx, client, &abr)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(requestsFor(builds)).To(Equal([]reconcile.Request{
+		{NamespacedName: types.NamespacedName{Namespace: namespace, Name: "created"}},
+		{NamespacedName: types.NamespacedName{Namespace: namespace, Name: "recorded"}},
+	}))
+
+	builds, err = listComponentBuilds(ctx, reconciler.client, namespace, ArtifactBuildIndex, abrName, "missing")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(builds).To(HaveLen(1))
+	g.Expect(builds[0].Name).To(Equal("recorded"))
+}
+
+func TestDependencyComponentBuilds(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, _ := setupClientAndReconciler()
+	ctx := context.TODO()
+	abrName := createBuiltArtifact(g, client, artifact, time.Now())
+	recorded := defaultComponentBuild()
+	recorded.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, ArtifactBuild: abrName}}
+	unrelated := defaultComponentBuild()
+	unrelated.Name = "unrelated"
+	unrelated.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV("com.test:other:2.0")}
+	for _, cb := range []*v1alpha2.ComponentBuild{&recorded, &unrelated} {
+		g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+	}
+	//the DependencyBuild also rebuilt an artifact that no ArtifactBuild asked for, it is found through the owners
+	db := jbs.DependencyBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: abrName + "-db"}, &db)).NotTo(HaveOccurred())
+	extra := jbs.RebuiltArtifact{ObjectMeta: metav1.ObjectMeta{Name: "extra", Namespace: namespace}, Spec: jbs.RebuiltArtifactSpec{GAV: "com.test:extra:1.0"}}
+	g.Expect(controllerutil.SetOwnerReference(&db, &extra, client.Scheme())).NotTo(HaveOccurred())
+	g.Expect(client.Create(ctx, &extra)).NotTo(HaveOccurred())
+	expected := []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}}}
+
+	artifacts, err := dependencyBuildArtifacts(ctx, client, &db)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(artifacts).To(HaveLen(2))
+	builds, err := rebuiltArtifactComponentBuilds(ctx, client, &extra)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(requestsFor(builds)).To(Equal(expected))
+}
@@ -0,0 +1,161 @@
+package componentbuild
+
+import (
+	"context"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	outcomeSucceeded = "succeeded"
+	outcomeFailed    = "failed"
+
+	// metricsListTimeout limits how long a scrape waits for the ComponentBuilds to be listed
+	metricsListTimeout = 10 * time.Second
+)
+
+var (
+	componentBuildDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "apheleia_componentbuild_duration_seconds",
+		Help:    "Time from the creation of a ComponentBuild until it completed or failed.",
+		Buckets: prometheus.ExponentialBuckets(60, 2, 12),
+	}, []string{"namespace", "state"})
+	deploys = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "apheleia_deploys_total",
+		Help: "Number of finished deploys, by outcome. Batched deploys are counted once per TaskRun.",
+	}, []string{"namespace", "outcome"})
+	deployDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "apheleia_deploy_duration_seconds",
+		Help:    "Time taken by finished deploys, by outcome.",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 12),
+	}, []string{"namespace", "outcome"})
+	notifications = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "apheleia_notifications_total",
+		Help: "Number of attempts to report the progress or result of a ComponentBuild, by notifier and outcome.",
+	}, []string{"namespace", "notifier", "outcome"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(componentBuildDuration, deploys, deployDuration, notifications)
+}
+
+// observeCompletion records how long the ComponentBuild took, if it has just completed or failed
+func observeCompletion(cb *v1alpha2.ComponentBuild, previous string) {
+	if cb.Status.State == previous || (cb.Status.State != v1alpha2.ComponentBuildStateComplete && cb.Status.State != v1alpha2.ComponentBuildStateFailed) {
+		return
+	}
+	componentBuildDuration.WithLabelValues(cb.Namespace, cb.Status.State).Observe(time.Since(cb.CreationTimestamp.Time).Seconds())
+}
+
+// observeDeploy records the outcome and duration of a deploy
+func observeDeploy(namespace string, succeeded bool, duration time.Duration) {
+	outcome := outcome(succeeded)
+	deploys.WithLabelValues(namespace, outcome).Inc()
+	deployDuration.WithLabelValues(namespace, outcome).Observe(duration.Seconds())
+}
+
+// observeDeployTaskRun records the outcome and duration of a deploy TaskRun
+func observeDeployTaskRun(tr *v1beta1.TaskRun, succeeded bool) {
+	var duration time.Duration
+	if tr.Status.StartTime != nil && tr.Status.CompletionTime != nil {
+		duration = tr.Status.CompletionTime.Sub(tr.Status.StartTime.Time)
+	}
+	observeDeploy(tr.Namespace, succeeded, duration)
+}
+
+// observeNotification records the outcome of reporting to the pull request
+func observeNotification(namespace string, notifier Notifier, err error) {
+	notifications.WithLabelValues(namespace, notifierName(notifier), outcome(err == nil)).Inc()
+}
+
+func notifierName(notifier Notifier) string {
+	switch notifier.(type) {
+	case *gitHubNotifier:
+		return v1alpha2.NotifierGitHub
+	case *gitLabNotifier:
+		return v1alpha2.NotifierGitLab
+	case *webhookNotifier:
+		return v1alpha2.NotifierWebhook
+	case *tektonNotifier:
+		return v1alpha2.NotifierTekton
+	}
+	return "unknown"
+}
+
+func outcome(succeeded bool) string {
+	if succeeded {
+		return outcomeSucceeded
+	}
+	return outcomeFailed
+}
+
+// componentBuildCollector reports gauges about the ComponentBuilds in the cache each time the metrics are scraped, so
+// that deleted ComponentBuilds are not reported
+type componentBuildCollector struct {
+	client client.Reader
+	builds *prometheus.Desc
+	// outstanding is the number of artifacts that are neither done nor failed
+	outstanding *prometheus.Desc
+	// oldest is the age of the oldest ComponentBuild that is still in progress
+	oldest *prometheus.Desc
+}
+
+func newComponentBuildCollector(client client.Reader) *componentBuildCollector {
+	return &componentBuildCollector{
+		client:      client,
+		builds:      prometheus.NewDesc("apheleia_componentbuilds", "Number of ComponentBuilds, by state.", []string{"namespace", "state"}, nil),
+		outstanding: prometheus.NewDesc("apheleia_componentbuild_outstanding_artifacts", "Number of artifacts that have not been built and deployed yet.", []string{"namespace"}, nil),
+		oldest:      prometheus.NewDesc("apheleia_componentbuild_oldest_in_progress_seconds", "Age of the oldest ComponentBuild that is in progress.", []string{"namespace"}, nil),
+	}
+}
+
+func (c *componentBuildCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.builds
+	ch <- c.outstanding
+	ch <- c.oldest
+}
+
+func (c *componentBuildCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), metricsListTimeout)
+	defer cancel()
+	cbList := v1alpha2.ComponentBuildList{}
+	if err := c.client.List(ctx, &cbList); err != nil {
+		ctrl.Log.WithName("componentbuild").Error(err, "Unable to list ComponentBuilds for metrics")
+		ch <- prometheus.NewInvalidMetric(c.builds, err)
+		return
+	}
+	type key struct{ namespace, state string }
+	builds := map[key]int{}
+	outstanding := map[string]int{}
+	oldest := map[string]time.Time{}
+	for _, cb := range cbList.Items {
+		state := cb.Status.State
+		if state == "" {
+			state = v1alpha2.ComponentBuildStateInProgress
+		}
+		builds[key{cb.Namespace, state}]++
+		outstanding[cb.Namespace] += cb.Status.Outstanding
+		if state == v1alpha2.ComponentBuildStateInProgress {
+			if created, ok := oldest[cb.Namespace]; !ok || cb.CreationTimestamp.Time.Before(created) {
+				oldest[cb.Namespace] = cb.CreationTimestamp.Time
+			}
+		}
+	}
+	for k, v := range builds {
+		ch <- prometheus.MustNewConstMetric(c.builds, prometheus.GaugeValue, float64(v), k.namespace, k.state)
+	}
+	for namespace, v := range outstanding {
+		ch <- prometheus.MustNewConstMetric(c.outstanding, prometheus.GaugeValue, float64(v), namespace)
+		age := 0.0
+		if created, ok := oldest[namespace]; ok {
+			age = time.Since(created).Seconds()
+		}
+		ch <- prometheus.MustNewConstMetric(c.oldest, prometheus.GaugeValue, age, namespace)
+	}
+}
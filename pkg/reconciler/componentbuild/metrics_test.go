@@ -0,0 +1,92 @@
+package componentbuild
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestComponentBuildCollector(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, _ := setupClientAndReconciler()
+	ctx := context.TODO()
+	for i, state := range []string{v1alpha2.ComponentBuildStateInProgress, v1alpha2.ComponentBuildStateInProgress, v1alpha2.ComponentBuildStateComplete} {
+		cb := defaultComponentBuild()
+		cb.Name = []string{"first", "second", "third"}[i]
+		cb.Status.State = state
+		cb.Status.Outstanding = 2
+		if state == v1alpha2.ComponentBuildStateComplete {
+			cb.Status.Outstanding = 0
+		}
+		g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	}
+
+	collector := newComponentBuildCollector(client)
+	g.Expect(testutil.CollectAndCount(collector)).To(Equal(4))
+	registry := prometheus.NewPedanticRegistry()
+	g.Expect(registry.Register(collector)).To(Succeed())
+	families, err := registry.Gather()
+	g.Expect(err).NotTo(HaveOccurred())
+	values := map[string]float64{}
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			key := family.GetName()
+			for _, label := range metric.Label {
+				if label.GetName() == "state" {
+					key += "/" + label.GetValue()
+				}
+			}
+			values[key] = metric.GetGauge().GetValue()
+		}
+	}
+	g.Expect(values).To(HaveKeyWithValue("apheleia_componentbuilds/"+v1alpha2.ComponentBuildStateInProgress, 2.0))
+	g.Expect(values).To(HaveKeyWithValue("apheleia_componentbuilds/"+v1alpha2.ComponentBuildStateComplete, 1.0))
+	g.Expect(values).To(HaveKeyWithValue("apheleia_componentbuild_outstanding_artifacts", 4.0))
+	g.Expect(values).To(HaveKey("apheleia_componentbuild_oldest_in_progress_seconds"))
+}
+
+func TestObserveCompletion(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cb := failedComponentBuild("")
+	cb.Namespace = "completion"
+	cb.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	observeCompletion(cb, v1alpha2.ComponentBuildStateInProgress)
+	//it is only observed when the state changes
+	observeCompletion(cb, v1alpha2.ComponentBuildStateFailed)
+
+	metric := dto.Metric{}
+	g.Expect(componentBuildDuration.WithLabelValues("completion", v1alpha2.ComponentBuildStateFailed).(prometheus.Histogram).Write(&metric)).To(Succeed())
+	g.Expect(metric.Histogram.GetSampleCount()).To(Equal(uint64(1)))
+	g.Expect(metric.Histogram.GetSampleSum()).To(BeNumerically("~", time.Hour.Seconds(), 60))
+}
+
+func TestNotificationMetrics(t *testing.T) {
+	g := NewGomegaWithT(t)
+	api := &notificationServer{status: http.StatusInternalServerError}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Notification = &v1alpha2.NotificationSettings{Provider: v1alpha2.NotifierWebhook, URL: server.URL}
+	})
+	cb := failedComponentBuild("https://example.com/pr/1")
+	failed := testutil.ToFloat64(notifications.WithLabelValues(namespace, v1alpha2.NotifierWebhook, outcomeFailed))
+	succeeded := testutil.ToFloat64(notifications.WithLabelValues(namespace, v1alpha2.NotifierWebhook, outcomeSucceeded))
+
+	g.Expect(reconciler.notifyResult(ctx, ctrl.Log, cb, cb.DeepCopy())).NotTo(Succeed())
+	g.Expect(testutil.ToFloat64(notifications.WithLabelValues(namespace, v1alpha2.NotifierWebhook, outcomeFailed))).To(Equal(failed + 1))
+	api.status = 0
+	g.Expect(reconciler.notifyResult(ctx, ctrl.Log, cb, cb.DeepCopy())).To(Succeed())
+	g.Expect(testutil.ToFloat64(notifications.WithLabelValues(namespace, v1alpha2.NotifierWebhook, outcomeSucceeded))).To(Equal(succeeded + 1))
+}
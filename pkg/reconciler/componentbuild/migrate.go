@@ -0,0 +1,125 @@
+package componentbuild
+
+import (
+	"context"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DeployedAnnotation is set by the deploy task on the RebuiltArtifacts it deployed. Earlier versions of the
+	// operator also set it on the DependencyBuild once its artifacts were deployed.
+	DeployedAnnotation = "io.aphelia/deployed"
+)
+
+// migrateDeployedAnnotations creates DeploymentRecords for the artifacts of DependencyBuilds that earlier versions of
+// the operator annotated as deployed. The annotation does not say which target the artifacts
+// were deployed to, so the current target of the namespace is assumed. Artifacts that already have a record for any
+// target have been migrated before and are left alone, the annotations themselves are not removed.
+func (r *ReconcileArtifactBuild) migrateDeployedAnnotations(ctx context.Context, log logr.Logger) error {
+	dbList := jvmbs.DependencyBuildList{}
+	err := r.client.List(ctx, &dbList)
+	if err != nil {
+		return err
+	}
+	targets := map[string]string{}
+	recorded := map[string]map[string]bool{}
+	for i := range dbList.Items {
+		db := &dbList.Items[i]
+		if db.Annotations[DeployedAnnotation] == "" {
+			continue
+		}
+		if _, ok := targets[db.Namespace]; !ok {
+			targets[db.Namespace], err = r.deploymentTargetName(ctx, db.Namespace)
+			if err != nil {
+				return err
+			}
+			recorded[db.Namespace], err = r.listRecordedArtifacts(ctx, db.Namespace)
+			if err != nil {
+				return err
+			}
+		}
+		target := targets[db.Namespace]
+		if target == "" {
+			log.Info("Not migrating deploy annotations, the namespace does not have a single deployment target", "namespace", db.Namespace, "name", db.Name)
+			continue
+		}
+		artifacts, err := dependencyBuildArtifacts(ctx, r.client, db)
+		if err != nil {
+			return err
+		}
+		for j := range artifacts {
+			ra := &artifacts[j]
+			if recorded[db.Namespace][ra.Name] {
+				continue
+			}
+			record, err := r.ensureDeploymentRecord(ctx, ra, target)
+			if err != nil {
+				return err
+			}
+			record.Status.Deployed = true
+			err = r.client.Status().Update(ctx, record)
+			if err != nil {
+				return err
+			}
+			recorded[db.Namespace][ra.Name] = true
+			log.Info("Migrated deploy annotations to a deployment record", "namespace", db.Namespace, "name", record.Name, "deployed", record.Status.Deployed)
+		}
+	}
+	return nil
+}
+
+// deploymentTargetName returns the name of the target of the namespace whether or not it is usable, or an empty
+// string if there is more than one
+func (r *ReconcileArtifactBuild) deploymentTargetName(ctx context.Context, namespace string) (string, error) {
+	targets := v1alpha2.DeploymentTargetList{}
+	err := r.client.List(ctx, &targets, client.InNamespace(namespace))
+	if err != nil {
+		return "", err
+	}
+	switch len(targets.Items) {
+	case 0:
+		return ApheleiaConfig, nil
+	case 1:
+		return targets.Items[0].Name, nil
+	}
+	return "", nil
+}
+
+// listRecordedArtifacts returns the names of the RebuiltArtifacts in the namespace that have a DeploymentRecord
+func (r *ReconcileArtifactBuild) listRecordedArtifacts(ctx context.Context, namespace string) (map[string]bool, error) {
+	records := v1alpha2.DeploymentRecordList{}
+	err := r.client.List(ctx, &records, client.InNamespace(namespace))
+	if err != nil {
+		return nil, err
+	}
+	recorded := map[string]bool{}
+	for _, i := range records.Items {
+		recorded[i.Spec.RebuiltArtifact] = true
+	}
+	return recorded, nil
+}
+
+// ownedBy returns true if the object has an owner reference of the given kind and name
+func ownedBy(o client.Object, kind string, name string) bool {
+	for _, ownerReference := range o.GetOwnerReferences() {
+		if ownerReference.Kind == kind && ownerReference.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// deployAnnotationMigration runs the migration once the manager has started, a failure is logged rather than stopping
+// the operator as the artifacts will just be deployed again
+func (r *ReconcileArtifactBuild) deployAnnotationMigration(ctx context.Context) error {
+	log := ctrl.Log.WithName("artifactbuild").WithName("migration")
+	if err := r.migrateDeployedAnnotations(ctx, log); err != nil {
+		log.Error(err, "Error migrating deploy annotations to deployment records")
+	}
+	return nil
+}
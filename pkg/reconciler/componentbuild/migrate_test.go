@@ -0,0 +1,66 @@
+package componentbuild
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestMigrateDeployedAnnotations(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	deployed := createBuiltArtifact(g, client, "com.test:deployed:1.0", time.Now())
+	undeployed := createBuiltArtifact(g, client, "com.test:undeployed:1.0", time.Now())
+	db := jbs.DependencyBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: deployed + "-db"}, &db)).NotTo(HaveOccurred())
+	db.Annotations = map[string]string{DeployedAnnotation: "true"}
+	g.Expect(client.Update(ctx, &db)).NotTo(HaveOccurred())
+
+	g.Expect(reconciler.migrateDeployedAnnotations(ctx, ctrl.Log)).To(Succeed())
+	record := v1alpha2.DeploymentRecord{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: deploymentRecordName(deployed, ApheleiaConfig)}, &record)).NotTo(HaveOccurred())
+	g.Expect(record.Spec.RebuiltArtifact).To(Equal(deployed))
+	g.Expect(record.Spec.GAV).To(Equal("com.test:deployed:1.0"))
+	g.Expect(record.Status.Deployed).To(BeTrue())
+	g.Expect(record.OwnerReferences).To(HaveLen(1))
+	g.Expect(record.OwnerReferences[0].Kind).To(Equal("RebuiltArtifact"))
+	//artifacts without the annotation get their record when they are deployed
+	g.Expect(errors.IsNotFound(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: deploymentRecordName(undeployed, ApheleiaConfig)}, &record))).To(BeTrue())
+
+	//running the migration again does not touch records that have changed since
+	record = v1alpha2.DeploymentRecord{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: deploymentRecordName(deployed, ApheleiaConfig)}, &record)).NotTo(HaveOccurred())
+	record.Status.Attempts = 3
+	g.Expect(client.Status().Update(ctx, &record)).NotTo(HaveOccurred())
+	g.Expect(reconciler.migrateDeployedAnnotations(ctx, ctrl.Log)).To(Succeed())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: record.Name}, &record)).NotTo(HaveOccurred())
+	g.Expect(record.Status.Attempts).To(Equal(3))
+
+	//the ComponentBuild sees the migrated artifact as deployed without deploying it again
+	cb := defaultComponentBuild()
+	cb.Spec.Artifacts = []v1alpha2.ArtifactSpec{v1alpha2.ParseGAV("com.test:deployed:1.0")}
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.GetArtifactState("com.test:deployed:1.0").Deployed).To(BeTrue())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateComplete))
+}
+
+func TestDeploymentRecordName(t *testing.T) {
+	g := NewGomegaWithT(t)
+	g.Expect(deploymentRecordName("com.test.test.1.0-abcde", "nexus")).To(Equal("com.test.test.1.0-abcde.nexus"))
+	long := deploymentRecordName(strings.Repeat("a", 250), "nexus")
+	g.Expect(len(long)).To(Equal(maxRecordNameLength))
+	g.Expect(long).NotTo(Equal(deploymentRecordName(strings.Repeat("a", 250), "mirror")))
+}
@@ -0,0 +1,230 @@
+package componentbuild
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/apheleia-project/apheleia/pkg/config"
+	"github.com/go-logr/logr"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	// DefaultNotifierSecret holds the git credentials of the namespace, as used by the build service, unless the
+	// controller configuration names another secret
+	DefaultNotifierSecret = config.DefaultNotifierSecret
+	// NotifierTokenKey is the key in the notifier secret that holds the API token
+	NotifierTokenKey = "token"
+	// GitCredentialsKey is the key in the notifier secret that holds git credentials, one URL per line, which are
+	// used if there is no token
+	GitCredentialsKey = ".git-credentials"
+	// notifierRequestTimeout limits how long a request to a pull request provider or webhook can take, so that a
+	// slow endpoint does not hold up a worker until the reconcile times out
+	notifierRequestTimeout = 30 * time.Second
+)
+
+// notifierClient is used for all requests to pull request providers and webhooks
+var notifierClient = &http.Client{Timeout: notifierRequestTimeout}
+
+// Notifier reports the result of a ComponentBuild back to the pull request it was created for
+type Notifier interface {
+	// Notify reports the message for the ComponentBuild. It returns true if the result has been reported, or false if
+	// it is still being reported and the result will be reported by a PipelineRun.
+	Notify(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, message string) (bool, error)
+}
+
+// newNotifier returns the Notifier for the ComponentBuild, as configured in the DeploymentTarget of the namespace or
+// detected from the PR URL
+func (r *ReconcileArtifactBuild) newNotifier(ctx context.Context, cb *v1alpha2.ComponentBuild, notification v1alpha2.NotificationSettings) (Notifier, error) {
+	provider := notification.Provider
+	if provider == "" {
+		provider = detectNotifier(cb.Spec.PRURL)
+	}
+	switch provider {
+	case v1alpha2.NotifierTekton:
+		settings := r.config.Get()
+		secret := notification.Secret
+		if secret == "" {
+			secret = settings.NotifierSecret
+		}
+		return &tektonNotifier{client: r.client, scheme: r.scheme, pipeline: settings.NotifierPipeline, secret: secret, workspaceSize: settings.NotifierWorkspaceSize}, nil
+	case v1alpha2.NotifierWebhook:
+		if notification.URL == "" {
+			return nil, fmt.Errorf("the %s notifier requires a notification url in the DeploymentTarget", v1alpha2.NotifierWebhook)
+		}
+		notifier := &webhookNotifier{client: notifierClient, url: notification.URL}
+		//webhooks do not need to be authenticated, so the default git credentials are not used
+		if name := notification.Secret; name != "" {
+			secret, err := r.secret(ctx, cb.Namespace, name)
+			if err != nil {
+				return nil, err
+			}
+			notifier.token = string(secret.Data[NotifierTokenKey])
+		}
+		return notifier, nil
+	case v1alpha2.NotifierGitHub, v1alpha2.NotifierGitLab:
+		pr, err := url.Parse(cb.Spec.PRURL)
+		if err != nil {
+			return nil, err
+		}
+		secret, token, err := r.gitToken(ctx, cb.Namespace, notification, pr.Host)
+		if err != nil {
+			return nil, err
+		}
+		if token == "" {
+			return nil, fmt.Errorf("no credentials for %s, secret %s must exist and contain a %s entry for it, or a %s if the host is in the notifierHosts of the controller configuration", pr.Host, secret, GitCredentialsKey, NotifierTokenKey)
+		}
+		return newGitNotifier(provider, notification, pr, token), nil
+	}
+	return nil, fmt.Errorf("unknown notification provider %q in the DeploymentTarget", provider)
+}
+
+// newGitNotifier returns the GitHub or GitLab notifier for the server that hosts the URL
+func newGitNotifier(provider string, notification v1alpha2.NotificationSettings, server *url.URL, token string) Notifier {
+	api := notification.URL
+	if provider == v1alpha2.NotifierGitHub {
+		if api == "" {
+			api = gitHubAPI(server)
+		}
+		return &gitHubNotifier{client: notifierClient, api: api, token: token}
+	}
+	if api == "" {
+		api = server.Scheme + "://" + server.Host + "/api/v4"
+	}
+	return &gitLabNotifier{client: notifierClient, api: api, token: token}
+}
+
+// gitToken returns the name of the notifier secret and the token in it for the host, the token is empty if the
+// secret does not exist or has no token for the host. The token key of the secret is not tied to a host, so it is only
+// used for the hosts in the controller configuration, or if the namespace configured the URL the notifier calls.
+// Otherwise the host must have its own entry in the git credentials, so credentials are never sent to a host that
+// is only named by the PR URL.
+func (r *ReconcileArtifactBuild) gitToken(ctx context.Context, namespace string, notification v1alpha2.NotificationSettings, host string) (string, string, error) {
+	name := notification.Secret
+	if name == "" {
+		name = r.config.Get().NotifierSecret
+	}
+	secret, err := r.secret(ctx, namespace, name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return name, "", nil
+		}
+		return name, "", err
+	}
+	return name, notifierToken(secret, host, notification.URL != "" || r.notifierHost(host)), nil
+}
+
+// notifierHost returns true if the token of the notifier secret may be sent to the host
+func (r *ReconcileArtifactBuild) notifierHost(host string) bool {
+	for _, allowed := range r.config.Get().NotifierHosts {
+		if strings.EqualFold(host, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// detectNotifier returns the provider for a PR URL, URLs that are not recognised are reported by the Tekton pipeline
+func detectNotifier(prURL string) string {
+	pr, err := url.Parse(prURL)
+	if err != nil {
+		return v1alpha2.NotifierTekton
+	}
+	if _, _, err := parseGitHubPR(pr); err == nil {
+		return v1alpha2.NotifierGitHub
+	}
+	if _, _, err := parseGitLabMR(pr); err == nil {
+		return v1alpha2.NotifierGitLab
+	}
+	return v1alpha2.NotifierTekton
+}
+
+// notifierToken returns the token in the secret if shared is true, or the password for the host in its git
+// credentials
+func notifierToken(secret *v1.Secret, host string, shared bool) string {
+	if token := string(secret.Data[NotifierTokenKey]); shared && token != "" {
+		return token
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(secret.Data[GitCredentialsKey]))
+	for scanner.Scan() {
+		credentials, err := url.Parse(strings.TrimSpace(scanner.Text()))
+		if err != nil || credentials.User == nil || !strings.EqualFold(credentials.Host, host) {
+			continue
+		}
+		if password, ok := credentials.User.Password(); ok {
+			return password
+		}
+		return credentials.User.Username()
+	}
+	return ""
+}
+
+// apiError is returned when an API responds with an unsuccessful status
+type apiError struct {
+	target string
+	status string
+	code   int
+	body   string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("request to %s failed with status %s", e.target, e.status)
+}
+
+// isNotFound returns true if the error is an API response saying that the resource does not exist
+func isNotFound(err error) bool {
+	apiErr, ok := err.(*apiError)
+	return ok && apiErr.code == http.StatusNotFound
+}
+
+// postJSON posts the body as JSON to the URL and checks that the response was successful
+func postJSON(ctx context.Context, client *http.Client, target string, header http.Header, body interface{}) error {
+	_, err := sendJSON(ctx, client, http.MethodPost, target, header, body)
+	return err
+}
+
+// sendJSON sends the body as JSON to the URL and returns the body of the response
+func sendJSON(ctx context.Context, client *http.Client, method string, target string, header http.Header, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	header = header.Clone()
+	header.Set("Content-Type", "application/json")
+	return apiRequest(ctx, client, method, target, header, bytes.NewReader(data))
+}
+
+// apiRequest sends the request and returns the body of the response, an unsuccessful status is returned as an
+// apiError
+func apiRequest(ctx context.Context, client *http.Client, method string, target string, header http.Header, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &apiError{target: target, status: resp.Status, code: resp.StatusCode, body: string(data)}
+	}
+	return data, nil
+}
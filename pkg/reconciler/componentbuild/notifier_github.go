@@ -0,0 +1,147 @@
+package componentbuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+)
+
+// gitHubNotifier reports the result as a comment on a GitHub pull request
+type gitHubNotifier struct {
+	client *http.Client
+	api    string
+	token  string
+}
+
+func (n *gitHubNotifier) Notify(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, message string) (bool, error) {
+	pr, err := url.Parse(cb.Spec.PRURL)
+	if err != nil {
+		return false, err
+	}
+	repository, number, err := parseGitHubPR(pr)
+	if err != nil {
+		return false, err
+	}
+	log.Info("Notifying ComponentBuild Status Update via GitHub PR Comment", "name", cb.Name, "PRURL", cb.Spec.PRURL, "state", cb.Status.State)
+	//pull requests share their comments with issues
+	endpoint := fmt.Sprintf("%s/repos/%s/issues/%d/comments", strings.TrimSuffix(n.api, "/"), repository, number)
+	err = postJSON(ctx, n.client, endpoint, n.header(), map[string]string{"body": message})
+	return err == nil, err
+}
+
+func (n *gitHubNotifier) ReportProgress(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, id string, body string) (string, error) {
+	pr, err := url.Parse(cb.Spec.PRURL)
+	if err != nil {
+		return "", err
+	}
+	repository, number, err := parseGitHubPR(pr)
+	if err != nil {
+		return "", err
+	}
+	api := strings.TrimSuffix(n.api, "/")
+	if id != "" {
+		log.Info("Updating ComponentBuild progress comment on GitHub PR", "name", cb.Name, "PRURL", cb.Spec.PRURL, "comment", id)
+		_, err := sendJSON(ctx, n.client, http.MethodPatch, fmt.Sprintf("%s/repos/%s/issues/comments/%s", api, repository, id), n.header(), map[string]string{"body": body})
+		//a comment that has been deleted is created again
+		if !isNotFound(err) {
+			return id, err
+		}
+	}
+	log.Info("Creating ComponentBuild progress comment on GitHub PR", "name", cb.Name, "PRURL", cb.Spec.PRURL)
+	data, err := sendJSON(ctx, n.client, http.MethodPost, fmt.Sprintf("%s/repos/%s/issues/%d/comments", api, repository, number), n.header(), map[string]string{"body": body})
+	if err != nil {
+		return "", err
+	}
+	return commentID(data)
+}
+
+func (n *gitHubNotifier) ResolveCommit(ctx context.Context, cb *v1alpha2.ComponentBuild) (string, error) {
+	if commitSHA.MatchString(cb.Spec.Tag) {
+		return cb.Spec.Tag, nil
+	}
+	repository, err := gitHubRepository(cb.Spec.SCMURL)
+	if err != nil {
+		return "", err
+	}
+	header := n.header()
+	header.Set("Accept", "application/vnd.github.sha")
+	endpoint := fmt.Sprintf("%s/repos/%s/commits/%s", strings.TrimSuffix(n.api, "/"), repository, url.PathEscape(cb.Spec.Tag))
+	sha, err := apiRequest(ctx, n.client, http.MethodGet, endpoint, header, nil)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(sha)), nil
+}
+
+func (n *gitHubNotifier) SetCommitStatus(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, sha string, state string, description string) error {
+	repository, err := gitHubRepository(cb.Spec.SCMURL)
+	if err != nil {
+		return err
+	}
+	log.Info("Setting GitHub commit status", "name", cb.Name, "repository", repository, "sha", sha, "state", state, "description", description)
+	endpoint := fmt.Sprintf("%s/repos/%s/statuses/%s", strings.TrimSuffix(n.api, "/"), repository, sha)
+	return postJSON(ctx, n.client, endpoint, n.header(), map[string]string{"state": state, "description": description, "context": CommitStatusContext})
+}
+
+func (n *gitHubNotifier) header() http.Header {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	header.Set("Authorization", "Bearer "+n.token)
+	return header
+}
+
+// commentID returns the numeric id from the JSON of a created GitHub comment or GitLab note
+func commentID(data []byte) (string, error) {
+	comment := struct {
+		ID json.Number `json:"id"`
+	}{}
+	if err := json.Unmarshal(data, &comment); err != nil {
+		return "", err
+	}
+	if comment.ID == "" {
+		return "", fmt.Errorf("the created comment has no id")
+	}
+	return comment.ID.String(), nil
+}
+
+// gitHubRepository returns the owner/repo of a repository URL
+func gitHubRepository(scmURL string) (string, error) {
+	path, err := repositoryPath(scmURL)
+	if err != nil {
+		return "", err
+	}
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 {
+		return "", fmt.Errorf("%s is not a GitHub repository URL", scmURL)
+	}
+	return parts[0] + "/" + parts[1], nil
+}
+
+// parseGitHubPR returns the owner/repo and number of a pull request URL such as
+// https://github.com/owner/repo/pull/1
+func parseGitHubPR(pr *url.URL) (string, int, error) {
+	parts := strings.Split(strings.Trim(pr.Path, "/"), "/")
+	if len(parts) < 4 || parts[2] != "pull" || parts[0] == "" || parts[1] == "" {
+		return "", 0, fmt.Errorf("%s is not a GitHub pull request URL", pr.String())
+	}
+	number, err := strconv.Atoi(parts[3])
+	if err != nil {
+		return "", 0, fmt.Errorf("%s is not a GitHub pull request URL", pr.String())
+	}
+	return parts[0] + "/" + parts[1], number, nil
+}
+
+// gitHubAPI returns the API URL for the server a pull request is on, GitHub Enterprise serves it under /api/v3
+func gitHubAPI(pr *url.URL) string {
+	if pr.Host == "github.com" || pr.Host == "www.github.com" {
+		return "https://api.github.com"
+	}
+	return pr.Scheme + "://" + pr.Host + "/api/v3"
+}
@@ -0,0 +1,128 @@
+package componentbuild
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+)
+
+// gitLabNotifier reports the result as a note on a GitLab merge request
+type gitLabNotifier struct {
+	client *http.Client
+	api    string
+	token  string
+}
+
+func (n *gitLabNotifier) Notify(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, message string) (bool, error) {
+	pr, err := url.Parse(cb.Spec.PRURL)
+	if err != nil {
+		return false, err
+	}
+	project, iid, err := parseGitLabMR(pr)
+	if err != nil {
+		return false, err
+	}
+	log.Info("Notifying ComponentBuild Status Update via GitLab MR Note", "name", cb.Name, "PRURL", cb.Spec.PRURL, "state", cb.Status.State)
+	endpoint := fmt.Sprintf("%s/projects/%s/merge_requests/%d/notes", strings.TrimSuffix(n.api, "/"), url.PathEscape(project), iid)
+	err = postJSON(ctx, n.client, endpoint, n.header(), map[string]string{"body": message})
+	return err == nil, err
+}
+
+func (n *gitLabNotifier) ReportProgress(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, id string, body string) (string, error) {
+	pr, err := url.Parse(cb.Spec.PRURL)
+	if err != nil {
+		return "", err
+	}
+	project, iid, err := parseGitLabMR(pr)
+	if err != nil {
+		return "", err
+	}
+	notes := fmt.Sprintf("%s/projects/%s/merge_requests/%d/notes", strings.TrimSuffix(n.api, "/"), url.PathEscape(project), iid)
+	if id != "" {
+		log.Info("Updating ComponentBuild progress note on GitLab MR", "name", cb.Name, "PRURL", cb.Spec.PRURL, "note", id)
+		_, err := sendJSON(ctx, n.client, http.MethodPut, notes+"/"+id, n.header(), map[string]string{"body": body})
+		//a note that has been deleted is created again
+		if !isNotFound(err) {
+			return id, err
+		}
+	}
+	log.Info("Creating ComponentBuild progress note on GitLab MR", "name", cb.Name, "PRURL", cb.Spec.PRURL)
+	data, err := sendJSON(ctx, n.client, http.MethodPost, notes, n.header(), map[string]string{"body": body})
+	if err != nil {
+		return "", err
+	}
+	return commentID(data)
+}
+
+func (n *gitLabNotifier) ResolveCommit(ctx context.Context, cb *v1alpha2.ComponentBuild) (string, error) {
+	if commitSHA.MatchString(cb.Spec.Tag) {
+		return cb.Spec.Tag, nil
+	}
+	project, err := repositoryPath(cb.Spec.SCMURL)
+	if err != nil {
+		return "", err
+	}
+	endpoint := fmt.Sprintf("%s/projects/%s/repository/commits/%s", strings.TrimSuffix(n.api, "/"), url.PathEscape(project), url.PathEscape(cb.Spec.Tag))
+	data, err := apiRequest(ctx, n.client, http.MethodGet, endpoint, n.header(), nil)
+	if err != nil {
+		return "", err
+	}
+	commit := struct {
+		ID string `json:"id"`
+	}{}
+	if err := json.Unmarshal(data, &commit); err != nil {
+		return "", err
+	}
+	return commit.ID, nil
+}
+
+func (n *gitLabNotifier) SetCommitStatus(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, sha string, state string, description string) error {
+	project, err := repositoryPath(cb.Spec.SCMURL)
+	if err != nil {
+		return err
+	}
+	//GitLab has its own names for the states, a build that is still in progress is running
+	switch state {
+	case CommitStatusPending:
+		state = "running"
+	case CommitStatusFailure:
+		state = "failed"
+	}
+	log.Info("Setting GitLab commit status", "name", cb.Name, "project", project, "sha", sha, "state", state, "description", description)
+	endpoint := fmt.Sprintf("%s/projects/%s/statuses/%s", strings.TrimSuffix(n.api, "/"), url.PathEscape(project), sha)
+	err = postJSON(ctx, n.client, endpoint, n.header(), map[string]string{"state": state, "description": description, "name": CommitStatusContext})
+	//setting the same state again is rejected, but the status is already what it should be
+	if apiErr, ok := err.(*apiError); ok && apiErr.code == http.StatusBadRequest && strings.Contains(apiErr.body, "Cannot transition status") {
+		return nil
+	}
+	return err
+}
+
+func (n *gitLabNotifier) header() http.Header {
+	header := http.Header{}
+	header.Set("PRIVATE-TOKEN", n.token)
+	return header
+}
+
+// parseGitLabMR returns the project path and internal id of a merge request URL such as
+// https://gitlab.com/group/project/-/merge_requests/1, the project may be in nested groups
+func parseGitLabMR(pr *url.URL) (string, int, error) {
+	path := strings.Trim(pr.Path, "/")
+	idx := strings.LastIndex(path, "/merge_requests/")
+	if idx <= 0 {
+		return "", 0, fmt.Errorf("%s is not a GitLab merge request URL", pr.String())
+	}
+	project := strings.TrimSuffix(path[:idx], "/-")
+	iid, err := strconv.Atoi(strings.Split(path[idx+len("/merge_requests/"):], "/")[0])
+	if err != nil || project == "" {
+		return "", 0, fmt.Errorf("%s is not a GitLab merge request URL", pr.String())
+	}
+	return project, iid, nil
+}
@@ -0,0 +1,87 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// tektonNotifier runs the notifier pipeline to comment on the pull request, the result is reported
+// once a PipelineRun with the same message has succeeded
+type tektonNotifier struct {
+	client client.Client
+	scheme *runtime.Scheme
+	// pipeline is the name of the Pipeline that comments on the pull request
+	pipeline string
+	// secret is the name of the secret with the git credentials that the pipeline uses
+	secret string
+	// workspaceSize is the size of the volume claimed for the workspace of the pipeline
+	workspaceSize resource.Quantity
+}
+
+func (n *tektonNotifier) Notify(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, message string) (bool, error) {
+	//first look for an existing PipelineRun - If none are found create a new one
+	existing := v1beta1.PipelineRunList{}
+	listOpts := &client.ListOptions{
+		Namespace:     cb.Namespace,
+		LabelSelector: labels.SelectorFromSet(map[string]string{NotifyPipelineLabel: cb.Name}),
+	}
+	err := n.client.List(ctx, &existing, listOpts)
+	if err != nil {
+		return false, err
+	}
+	for _, i := range existing.Items {
+		if i.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+			return false, nil
+		}
+		if i.Status.GetCondition(apis.ConditionSucceeded).IsTrue() && pipelineRunParam(&i, "message") == message {
+			return true, nil
+		}
+	}
+	tr := &v1beta1.PipelineRun{}
+	tr.GenerateName = cb.Name + "-notify-pipeline"
+	tr.Namespace = cb.Namespace
+	cerr := controllerutil.SetControllerReference(cb, tr, n.scheme)
+	if cerr != nil {
+		log.Error(cerr, fmt.Sprintf("Error setting controller reference for pipelinerun %s", tr.Name))
+	}
+	tr.Labels = map[string]string{NotifyPipelineLabel: cb.Name}
+	tr.Spec.PipelineRef = &v1beta1.PipelineRef{Name: n.pipeline}
+	tr.Spec.Params = []v1beta1.Param{
+		{Name: "url", Value: v1beta1.ArrayOrString{StringVal: cb.Spec.PRURL, Type: v1beta1.ParamTypeString}},
+		{Name: "secret-key-ref", Value: v1beta1.ArrayOrString{StringVal: n.secret, Type: v1beta1.ParamTypeString}},
+		{Name: "message", Value: v1beta1.ArrayOrString{StringVal: message, Type: v1beta1.ParamTypeString}},
+	}
+	tr.Spec.Workspaces = []v1beta1.WorkspaceBinding{
+		{Name: "pr", VolumeClaimTemplate: &v1.PersistentVolumeClaim{
+			Spec: v1.PersistentVolumeClaimSpec{
+				AccessModes: []v1.PersistentVolumeAccessMode{v1.ReadWriteOnce},
+				Resources: v1.ResourceRequirements{
+					Requests: map[v1.ResourceName]resource.Quantity{"storage": n.workspaceSize},
+				},
+			},
+		}},
+	}
+	log.Info("Notifying ComponentBuild Status Update via PR Comment", "name", cb.Name, "scmUrl", cb.Spec.SCMURL, "PRURL", cb.Spec.PRURL, "state", cb.Status.State)
+	return false, n.client.Create(ctx, tr)
+}
+
+// pipelineRunParam returns the value of a string param of the PipelineRun
+func pipelineRunParam(pr *v1beta1.PipelineRun, name string) string {
+	for _, param := range pr.Spec.Params {
+		if param.Name == name {
+			return param.Value.StringVal
+		}
+	}
+	return ""
+}
@@ -0,0 +1,48 @@
+package componentbuild
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+)
+
+// webhookPayload is the JSON that is posted to the webhook
+type webhookPayload struct {
+	Name      string                   `json:"name"`
+	Namespace string                   `json:"namespace"`
+	SCMURL    string                   `json:"scmURL,omitempty"`
+	PRURL     string                   `json:"prURL,omitempty"`
+	Tag       string                   `json:"tag,omitempty"`
+	State     string                   `json:"state"`
+	Message   string                   `json:"message"`
+	Artifacts []v1alpha2.ArtifactState `json:"artifacts,omitempty"`
+}
+
+// webhookNotifier posts the result as JSON to a URL, if a token is configured it is sent as a bearer token
+type webhookNotifier struct {
+	client *http.Client
+	url    string
+	token  string
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, message string) (bool, error) {
+	log.Info("Notifying ComponentBuild Status Update via webhook", "name", cb.Name, "PRURL", cb.Spec.PRURL, "state", cb.Status.State)
+	header := http.Header{}
+	if n.token != "" {
+		header.Set("Authorization", "Bearer "+n.token)
+	}
+	payload := webhookPayload{
+		Name:      cb.Name,
+		Namespace: cb.Namespace,
+		SCMURL:    cb.Spec.SCMURL,
+		PRURL:     cb.Spec.PRURL,
+		Tag:       cb.Spec.Tag,
+		State:     cb.Status.State,
+		Message:   message,
+		Artifacts: cb.Status.ArtifactState,
+	}
+	err := postJSON(ctx, n.client, n.url, header, payload)
+	return err == nil, err
+}
@@ -0,0 +1,39 @@
+package componentbuild
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcilePipelineRun cleans up after notify PipelineRuns once they finish. Their result is recorded by the
+// ComponentBuild controller, which finds the PipelineRun when it notifies again.
+type ReconcilePipelineRun struct {
+	*ReconcileArtifactBuild
+}
+
+func (r *ReconcilePipelineRun) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, r.config.Get().ReconcileTimeout.Duration)
+	defer cancel()
+	log := ctrl.Log.WithName("pipelinerun").WithValues("request", request.NamespacedName)
+
+	pr := v1beta1.PipelineRun{}
+	err := r.client.Get(ctx, request.NamespacedName, &pr)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Error(err, "Reconcile key %s as pipelinerun unexpected error", request.NamespacedName.String())
+		return reconcile.Result{}, err
+	}
+	if pr.Labels[NotifyPipelineLabel] == "" || pr.Status.CompletionTime == nil {
+		return reconcile.Result{}, nil
+	}
+	log.Info("Handling PipelineRun", "name", pr.Name)
+	r.pruneNamespaceRuns(ctx, log, pr.Namespace)
+	return reconcile.Result{}, nil
+}
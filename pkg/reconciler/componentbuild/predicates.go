@@ -0,0 +1,74 @@
+package componentbuild
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var (
+	// runStatusChanged passes the events of TaskRuns and PipelineRuns that can change the result of a reconcile. Runs
+	// are only of interest once they finish, so updates are passed when the completion time or the status of the
+	// Succeeded condition changes, and runs that already exist are passed when they have finished. Deleting a run that
+	// has not finished is passed as it will never complete.
+	runStatusChanged = predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			completion, _ := runStatus(e.Object)
+			return completion != nil
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCompletion, oldSucceeded := runStatus(e.ObjectOld)
+			newCompletion, newSucceeded := runStatus(e.ObjectNew)
+			return !oldCompletion.Equal(newCompletion) || oldSucceeded != newSucceeded
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			completion, _ := runStatus(e.Object)
+			return completion == nil
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+	// deployTaskRunPredicates passes the status changes of deploy TaskRuns
+	deployTaskRunPredicates = predicate.And(predicate.NewPredicateFuncs(func(o client.Object) bool {
+		tr, ok := o.(*v1beta1.TaskRun)
+		return ok && isDeployTaskRun(tr)
+	}), runStatusChanged)
+	// notifyPipelineRunPredicates passes the status changes of notify PipelineRuns
+	notifyPipelineRunPredicates = predicate.And(predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetLabels()[NotifyPipelineLabel] != ""
+	}), runStatusChanged)
+)
+
+// runStatus returns the completion time and the status of the Succeeded condition of a TaskRun or PipelineRun, a run
+// without the condition has not started yet so its status is unknown
+func runStatus(o client.Object) (*metav1.Time, v1.ConditionStatus) {
+	var completion *metav1.Time
+	var succeeded *apis.Condition
+	switch run := o.(type) {
+	case *v1beta1.TaskRun:
+		completion, succeeded = run.Status.CompletionTime, run.Status.GetCondition(apis.ConditionSucceeded)
+	case *v1beta1.PipelineRun:
+		completion, succeeded = run.Status.CompletionTime, run.Status.GetCondition(apis.ConditionSucceeded)
+	}
+	if succeeded == nil {
+		return completion, v1.ConditionUnknown
+	}
+	return completion, succeeded.Status
+}
+
+// notifyPipelineRunRequests maps a notify PipelineRun to its ComponentBuild, which records the result once the
+// PipelineRun succeeds
+func notifyPipelineRunRequests(o client.Object) []reconcile.Request {
+	cb := o.GetLabels()[NotifyPipelineLabel]
+	if cb == "" {
+		return nil
+	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: o.GetNamespace(), Name: cb}}}
+}
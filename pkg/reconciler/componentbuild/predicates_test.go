@@ -0,0 +1,112 @@
+package componentbuild
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// pipelineRunEvents returns the events of the PipelineRun from creation to completion, with an update that only
+// changes the metadata and one that moves it from pending to running
+func pipelineRunEvents(name string, labels map[string]string) (event.CreateEvent, []event.UpdateEvent) {
+	pending := &v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels}}
+	pending.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionUnknown, Reason: "Pending"})
+	annotated := pending.DeepCopy()
+	annotated.Annotations = map[string]string{"results.tekton.dev/log": "log"}
+	running := annotated.DeepCopy()
+	running.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionUnknown, Reason: "Running"})
+	completed := running.DeepCopy()
+	completed.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	completed.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue, Reason: "Succeeded"})
+	return event.CreateEvent{Object: pending}, []event.UpdateEvent{
+		{ObjectOld: pending, ObjectNew: annotated},
+		{ObjectOld: annotated, ObjectNew: running},
+		{ObjectOld: running, ObjectNew: completed},
+	}
+}
+
+// queuePipelineRunEvents sends the events of unrelated build PipelineRuns and of one notify PipelineRun through the
+// predicates and map function of the ComponentBuild controller, and returns how many events passed the predicates
+func queuePipelineRunEvents(unrelated int, q workqueue.RateLimitingInterface) int {
+	h := handler.EnqueueRequestsFromMapFunc(notifyPipelineRunRequests)
+	passed := 0
+	send := func(p predicate.Predicate, create event.CreateEvent, updates []event.UpdateEvent) {
+		if p.Create(create) {
+			passed++
+			h.Create(create, q)
+		}
+		for _, update := range updates {
+			if p.Update(update) {
+				passed++
+				h.Update(update, q)
+			}
+		}
+	}
+	for i := 0; i < unrelated; i++ {
+		create, updates := pipelineRunEvents(fmt.Sprintf("build-%d", i), map[string]string{"tekton.dev/pipeline": "default-build"})
+		send(notifyPipelineRunPredicates, create, updates)
+	}
+	create, updates := pipelineRunEvents("test-notify-pipeline", map[string]string{NotifyPipelineLabel: name})
+	send(notifyPipelineRunPredicates, create, updates)
+	return passed
+}
+
+func TestNotifyPipelineRunPredicates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	defer q.ShutDown()
+	//only the completion of the notify PipelineRun reaches the queue
+	g.Expect(queuePipelineRunEvents(1000, q)).To(Equal(1))
+	g.Expect(q.Len()).To(Equal(1))
+	item, _ := q.Get()
+	g.Expect(fmt.Sprint(item)).To(Equal(namespace + "/" + name))
+
+	//a notify PipelineRun that is deleted before it finishes is passed, as it will never complete
+	create, _ := pipelineRunEvents("test-notify-pipeline", map[string]string{NotifyPipelineLabel: name})
+	g.Expect(notifyPipelineRunPredicates.Delete(event.DeleteEvent{Object: create.Object})).To(BeTrue())
+}
+
+func TestDeployTaskRunPredicates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	running := &v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "deploy", Namespace: namespace, Labels: map[string]string{DeployTaskLabel: "record"}}}
+	running.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionUnknown, Reason: "Running"})
+	relabeled := running.DeepCopy()
+	relabeled.Labels["other"] = "value"
+	failed := running.DeepCopy()
+	failed.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	failed.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionFalse, Reason: "Failed"})
+	build := failed.DeepCopy()
+	build.Labels = map[string]string{"tekton.dev/task": "build"}
+
+	g.Expect(deployTaskRunPredicates.Create(event.CreateEvent{Object: running})).To(BeFalse())
+	g.Expect(deployTaskRunPredicates.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: relabeled})).To(BeFalse())
+	g.Expect(deployTaskRunPredicates.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: failed})).To(BeTrue())
+	//finished runs are passed when the cache starts, so results recorded while the operator was down are not lost
+	g.Expect(deployTaskRunPredicates.Create(event.CreateEvent{Object: failed})).To(BeTrue())
+	g.Expect(deployTaskRunPredicates.Create(event.CreateEvent{Object: build})).To(BeFalse())
+	g.Expect(deployTaskRunPredicates.Delete(event.DeleteEvent{Object: failed})).To(BeFalse())
+}
+
+// BenchmarkPipelineRunEvents reports how many reconcile requests reach the work queue of the ComponentBuild
+// controller while thousands of unrelated PipelineRuns run, compared to the number of events they generate
+func BenchmarkPipelineRunEvents(b *testing.B) {
+	const unrelated = 5000
+	for i := 0; i < b.N; i++ {
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		passed := queuePipelineRunEvents(unrelated, q)
+		b.ReportMetric(float64((unrelated+1)*4), "events/op")
+		b.ReportMetric(float64(passed), "passed/op")
+		b.ReportMetric(float64(q.Len()), "queued/op")
+		q.ShutDown()
+	}
+}
@@ -0,0 +1,111 @@
+package componentbuild
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ProgressReporter is implemented by notifiers that can keep a single comment on the pull request up to date
+type ProgressReporter interface {
+	// ReportProgress edits the comment with the given id, or creates a new comment if the id is empty or the comment
+	// no longer exists. It returns the id of the comment.
+	ReportProgress(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, id string, body string) (string, error)
+}
+
+// reportProgress creates or edits the progress comment, and records its id and the digest of the body in the status.
+// An empty comment is claimed in the status before a comment is created, which fails if the ComponentBuild was read
+// from a cache that does not show the comment yet. The id of a new comment is written to the status straight away,
+// so that nothing that happens later in the reconcile can lose it and create a second comment.
+func (r *ReconcileArtifactBuild) reportProgress(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, original *v1alpha2.ComponentBuild, reporter ProgressReporter, body string) error {
+	id := ""
+	if cb.Status.ProgressComment != nil {
+		id = cb.Status.ProgressComment.ID
+	}
+	if id == "" {
+		err := r.recordStatus(ctx, cb, original, func(s *v1alpha2.ComponentBuildStatus) {
+			s.ProgressComment = &v1alpha2.PullRequestComment{}
+		})
+		if errors.IsConflict(err) {
+			//the ComponentBuild is reconciled again once the status has been written
+			log.Info("ComponentBuild changed before the progress comment was created, it will be created by the next reconcile", "name", cb.Name)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+	created, err := reporter.ReportProgress(ctx, log, cb, id, body)
+	if err != nil {
+		return err
+	}
+	comment := &v1alpha2.PullRequestComment{ID: created, Digest: commentDigest(body)}
+	if created != id {
+		record := func(s *v1alpha2.ComponentBuildStatus) {
+			s.ProgressComment = comment.DeepCopy()
+		}
+		err = r.recordStatus(ctx, cb, original, record)
+		if errors.IsConflict(err) {
+			//the comment exists, so its id is written even though the ComponentBuild has changed. Only the comment is
+			//in the patch, the rest of the status is computed again when it is written.
+			updated := original.DeepCopy()
+			record(&updated.Status)
+			err = r.client.Status().Patch(ctx, updated, client.MergeFrom(original))
+		}
+	}
+	cb.Status.ProgressComment = comment
+	return err
+}
+
+// progressUpToDate returns true if the progress comment has already been written with the body
+func progressUpToDate(cb *v1alpha2.ComponentBuild, body string) bool {
+	return cb.Status.ProgressComment != nil && cb.Status.ProgressComment.Digest == commentDigest(body)
+}
+
+func commentDigest(body string) string {
+	digest := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(digest[:])
+}
+
+// progressComment renders the body of the progress comment, the message followed by a table with the state of each
+// artifact. If the console URL is set the ArtifactBuilds link to it.
+func progressComment(consoleURL string, cb *v1alpha2.ComponentBuild, message string) string {
+	console := strings.TrimSuffix(consoleURL, "/")
+	body := strings.Builder{}
+	body.WriteString(message)
+	body.WriteString("\n\n| Artifact | State | ArtifactBuild |\n| --- | --- | --- |\n")
+	for _, state := range cb.Status.ArtifactState {
+		abr := "`" + state.ArtifactBuild + "`"
+		if console != "" {
+			abr = fmt.Sprintf("[%s](%s/k8s/ns/%s/jvmbuildservice.io~v1alpha1~ArtifactBuild/%s)", state.ArtifactBuild, console, cb.Namespace, state.ArtifactBuild)
+		}
+		body.WriteString(fmt.Sprintf("| `%s` | %s | %s |\n", state.GAV, artifactStateLabel(state), abr))
+	}
+	return body.String()
+}
+
+// artifactStateLabel describes the state of an artifact in the progress comment
+func artifactStateLabel(state v1alpha2.ArtifactState) string {
+	switch {
+	case state.Failed:
+		return "Build failed"
+	case state.DeployFailed:
+		return "Deploy failed"
+	case state.Done():
+		return "Deployed"
+	case state.Deploying:
+		return "Deploying"
+	case state.Queued:
+		return "Waiting to deploy"
+	case state.Built:
+		return "Built"
+	}
+	return "Building"
+}
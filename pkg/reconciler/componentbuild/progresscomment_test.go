@@ -0,0 +1,165 @@
+package componentbuild
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestProgressCommentBody(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cb := defaultComponentBuild()
+	cb.Status.State = v1alpha2.ComponentBuildStateInProgress
+	cb.Status.Outstanding = 1
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{
+		{GAV: artifact, ArtifactBuild: "test.1.0-abcde", Built: true, Deployed: true},
+		{GAV: "com.test:other:1.0", ArtifactBuild: "other.1.0-abcde", Built: true, Queued: true},
+	}
+	message, err := notificationMessage(v1alpha2.NotificationSettings{}, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(progressComment("", &cb, message)).To(Equal("Rebuilding dependencies, 1 of 2 artifacts are outstanding.\n\n" +
+		"| Artifact | State | ArtifactBuild |\n| --- | --- | --- |\n" +
+		"| `com.test:test:1.0` | Deployed | `test.1.0-abcde` |\n" +
+		"| `com.test:other:1.0` | Waiting to deploy | `other.1.0-abcde` |\n"))
+
+	cb.Status.State = v1alpha2.ComponentBuildStateFailed
+	cb.Status.ArtifactState[1] = v1alpha2.ArtifactState{GAV: "com.test:other:1.0", ArtifactBuild: "other.1.0-abcde", Failed: true}
+	message, err = notificationMessage(v1alpha2.NotificationSettings{}, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	body := progressComment("https://console.example.com/", &cb, message)
+	g.Expect(body).To(HavePrefix("The following dependency builds have failed: com.test:other:1.0.\n\n"))
+	g.Expect(body).To(ContainSubstring("| `com.test:other:1.0` | Build failed | [other.1.0-abcde](https://console.example.com/k8s/ns/default/jvmbuildservice.io~v1alpha1~ArtifactBuild/other.1.0-abcde) |\n"))
+}
+
+func TestProgressCommentUpdatedInPlace(t *testing.T) {
+	g := NewGomegaWithT(t)
+	api := &notificationServer{response: `{"id":42}`}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	secret := v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: DefaultNotifierSecret, Namespace: namespace}, Data: map[string][]byte{NotifierTokenKey: []byte("secret")}}
+	client, reconciler := setupClientAndReconciler(&secret)
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Notification = &v1alpha2.NotificationSettings{URL: server.URL}
+	})
+	cb := defaultComponentBuild()
+	cb.Spec.PRURL = "https://github.com/test/test/pull/1"
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+
+	//the comment is created when the build starts
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(api.requests).To(Equal(1))
+	g.Expect(api.method).To(Equal(http.MethodPost))
+	g.Expect(api.path).To(Equal("/repos/test/test/issues/1/comments"))
+	g.Expect(api.body["body"]).To(ContainSubstring("| Building |"))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.ProgressComment).NotTo(BeNil())
+	g.Expect(cb.Status.ProgressComment.ID).To(Equal("42"))
+	g.Expect(cb.Status.ResultNotified).To(BeFalse())
+
+	//nothing has changed, so the comment is not edited
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(api.requests).To(Equal(1))
+
+	//the final state is written to the same comment
+	abr := jbs.ArtifactBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(artifact)}, &abr)).NotTo(HaveOccurred())
+	abr.Status.State = jbs.ArtifactBuildStateFailed
+	g.Expect(client.Status().Update(ctx, &abr)).NotTo(HaveOccurred())
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(api.requests).To(Equal(2))
+	g.Expect(api.method).To(Equal(http.MethodPatch))
+	g.Expect(api.path).To(Equal("/repos/test/test/issues/comments/42"))
+	g.Expect(api.body["body"]).To(ContainSubstring("| Build failed |"))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.ResultNotified).To(BeTrue())
+	g.Expect(cb.Status.ProgressComment.ID).To(Equal("42"))
+}
+
+func TestProgressCommentRecreated(t *testing.T) {
+	g := NewGomegaWithT(t)
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.EscapedPath())
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id":7}`))
+	}))
+	defer server.Close()
+
+	//a note that was deleted from the merge request is created again
+	notifier := &gitLabNotifier{client: server.Client(), api: server.URL, token: "secret"}
+	cb := defaultComponentBuild()
+	cb.Spec.PRURL = "https://gitlab.com/test/test/-/merge_requests/3"
+	id, err := notifier.ReportProgress(context.TODO(), ctrl.Log, &cb, "5", "body")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(id).To(Equal("7"))
+	g.Expect(methods).To(Equal([]string{"PUT /projects/test%2Ftest/merge_requests/3/notes/5", "POST /projects/test%2Ftest/merge_requests/3/notes"}))
+}
+
+// countingReporter creates a new comment each time it is asked to create one
+type countingReporter struct {
+	created int
+}
+
+func (c *countingReporter) ReportProgress(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, id string, body string) (string, error) {
+	if id == "" {
+		c.created++
+		id = strconv.Itoa(c.created)
+	}
+	return id, nil
+}
+
+func TestProgressCommentRecordedWhenCreated(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	stale := cb.DeepCopy()
+
+	//the ComponentBuild changes after the comment is created, the id is still written straight away
+	reporter := &countingReporter{}
+	interleaving := &interleavingClient{Client: client, skip: 1}
+	racing := *reconciler
+	racing.client = interleaving
+	interleaving.before = func() {
+		changed := v1alpha2.ComponentBuild{}
+		g.Expect(client.Get(ctx, cbName, &changed)).NotTo(HaveOccurred())
+		changed.Annotations = map[string]string{"changed": "true"}
+		g.Expect(client.Update(ctx, &changed)).NotTo(HaveOccurred())
+	}
+	g.Expect(racing.reportProgress(ctx, ctrl.Log, &cb, cb.DeepCopy(), reporter, "body")).NotTo(HaveOccurred())
+	g.Expect(reporter.created).To(Equal(1))
+	g.Expect(interleaving.patches).To(Equal(3))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.ProgressComment).NotTo(BeNil())
+	g.Expect(cb.Status.ProgressComment.ID).To(Equal("1"))
+
+	//a reconcile that read the ComponentBuild before the comment existed does not create a second one
+	g.Expect(reconciler.reportProgress(ctx, ctrl.Log, stale, stale.DeepCopy(), reporter, "body")).NotTo(HaveOccurred())
+	g.Expect(reporter.created).To(Equal(1))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.ProgressComment.ID).To(Equal("1"))
+}
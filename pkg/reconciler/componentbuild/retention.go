@@ -0,0 +1,223 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// KeepAnnotation can be set to true on a ComponentBuild, TaskRun or PipelineRun to exclude it from cleanup
+	KeepAnnotation = "apheleia.io/keep"
+)
+
+// retentionPolicy is read from the DeploymentTarget of the namespace, by default nothing is cleaned up
+type retentionPolicy struct {
+	// artifacts is ArtifactRetentionRetain or ArtifactRetentionDelete
+	artifacts string
+	// ttl is how long a finished ComponentBuild is kept, zero keeps them forever
+	ttl time.Duration
+	// keepRuns is how many finished runs of each kind are kept, a negative value keeps all of them
+	keepRuns int
+}
+
+// retentionPolicy returns the retention policy of the namespace. Invalid values are logged and ignored, so a target
+// that was created without the webhook never causes anything to be deleted by mistake.
+func (r *ReconcileArtifactBuild) retentionPolicy(ctx context.Context, log logr.Logger, namespace string) (retentionPolicy, error) {
+	policy := retentionPolicy{artifacts: v1alpha2.ArtifactRetentionRetain, keepRuns: -1}
+	settings, err := r.namespaceSettings(ctx, namespace)
+	if err != nil {
+		return policy, err
+	}
+	retention := settings.GetRetention()
+	switch retention.Artifacts {
+	case "", v1alpha2.ArtifactRetentionRetain:
+	case v1alpha2.ArtifactRetentionDelete:
+		policy.artifacts = v1alpha2.ArtifactRetentionDelete
+	default:
+		log.Info(fmt.Sprintf("Unknown artifact retention %q in the DeploymentTarget, ArtifactBuilds are retained", retention.Artifacts), "namespace", namespace)
+	}
+	if ttl := retention.ComponentBuildTTL; ttl != nil {
+		if ttl.Duration < 0 {
+			log.Info(fmt.Sprintf("Invalid ComponentBuild TTL %s in the DeploymentTarget, ComponentBuilds are kept", ttl.Duration), "namespace", namespace)
+		} else {
+			policy.ttl = ttl.Duration
+		}
+	}
+	if keep := retention.KeepRuns; keep != nil {
+		if *keep < 0 {
+			log.Info(fmt.Sprintf("Invalid number of runs to keep %d in the DeploymentTarget, runs are kept", *keep), "namespace", namespace)
+		} else {
+			policy.keepRuns = *keep
+		}
+	}
+	return policy, nil
+}
+
+// keep returns true if the object is annotated to be excluded from cleanup
+func keep(o client.Object) bool {
+	value, err := strconv.ParseBool(o.GetAnnotations()[KeepAnnotation])
+	return err == nil && value
+}
+
+// expireComponentBuild deletes the ComponentBuild once it has been finished for longer than the TTL. The result
+// has to be reported first, as the notify PipelineRun is deleted along with the ComponentBuild. If the ComponentBuild
+// has not expired yet it returns how long until it does, or zero if it never expires.
+func (r *ReconcileArtifactBuild) expireComponentBuild(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, policy retentionPolicy) (time.Duration, error) {
+	if policy.ttl == 0 || keep(cb) {
+		return 0, nil
+	}
+	if cb.Status.State != v1alpha2.ComponentBuildStateComplete && cb.Status.State != v1alpha2.ComponentBuildStateFailed {
+		return 0, nil
+	}
+	if cb.Spec.PRURL != "" && !cb.Status.ResultNotified {
+		return 0, nil
+	}
+	ready := meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionReady)
+	if ready == nil {
+		return 0, nil
+	}
+	remaining := time.Until(ready.LastTransitionTime.Add(policy.ttl))
+	if remaining > 0 {
+		return remaining, nil
+	}
+	log.Info("Deleting finished ComponentBuild as it is older than the TTL", "name", cb.Name, "finished", ready.LastTransitionTime, "ttl", policy.ttl)
+	return 0, client.IgnoreNotFound(r.client.Delete(ctx, cb))
+}
+
+// pruneRuns deletes the finished deploy TaskRuns and notify PipelineRuns in the namespace beyond the newest ones
+// that the policy keeps. Deploy TaskRuns are only deleted once their result is held by the DeploymentRecords, as the
+// result would be lost and the artifacts deployed again otherwise.
+func (r *ReconcileArtifactBuild) pruneRuns(ctx context.Context, log logr.Logger, namespace string, policy retentionPolicy) error {
+	if policy.keepRuns < 0 {
+		return nil
+	}
+	taskRuns := v1beta1.TaskRunList{}
+	err := r.client.List(ctx, &taskRuns, client.InNamespace(namespace), client.HasLabels{DeployTaskLabel})
+	if err != nil {
+		return err
+	}
+	var finished []client.Object
+	completed := map[client.Object]*metav1.Time{}
+	for i := range taskRuns.Items {
+		tr := &taskRuns.Items[i]
+		if tr.Status.CompletionTime == nil || keep(tr) {
+			continue
+		}
+		recorded, err := r.deployResultRecorded(ctx, tr)
+		if err != nil {
+			return err
+		}
+		if recorded {
+			finished = append(finished, tr)
+			completed[tr] = tr.Status.CompletionTime
+		}
+	}
+	err = r.deleteOldest(ctx, log, finished, completed, policy.keepRuns)
+	if err != nil {
+		return err
+	}
+	pipelineRuns := v1beta1.PipelineRunList{}
+	err = r.client.List(ctx, &pipelineRuns, client.InNamespace(namespace), client.HasLabels{NotifyPipelineLabel})
+	if err != nil {
+		return err
+	}
+	finished = nil
+	for i := range pipelineRuns.Items {
+		pr := &pipelineRuns.Items[i]
+		if pr.Status.CompletionTime != nil && !keep(pr) && !r.awaitingNotified(ctx, pr) {
+			finished = append(finished, pr)
+			completed[pr] = pr.Status.CompletionTime
+		}
+	}
+	return r.deleteOldest(ctx, log, finished, completed, policy.keepRuns)
+}
+
+// deployResultRecorded returns true if the result of the finished deploy TaskRun is held by the DeploymentRecords of its
+// artifacts. An artifact that the TaskRun deployed is recorded once its record is deployed, a failure once the record
+// holds a failure at or after the completion of the TaskRun. Artifacts without a record have nothing to record.
+func (r *ReconcileArtifactBuild) deployResultRecorded(ctx context.Context, tr *v1beta1.TaskRun) (bool, error) {
+	//the record names mapped to whether the TaskRun deployed the artifact
+	records := map[string]bool{}
+	if tr.Labels[BatchDeployTaskLabel] == "" {
+		records[deployTaskRecord(tr)] = tr.Status.GetCondition(apis.ConditionSucceeded).IsTrue()
+	} else {
+		deployed := map[string]bool{}
+		for _, name := range strings.Split(taskRunResult(tr, DeployedResult), ",") {
+			deployed[strings.TrimSpace(name)] = true
+		}
+		for _, name := range batchArtifacts(tr) {
+			records[deploymentRecordName(name, tr.Annotations[DeploymentTargetAnnotation])] = deployed[name]
+		}
+	}
+	for name, deployed := range records {
+		record, err := r.getDeploymentRecord(ctx, tr.Namespace, name)
+		if err != nil {
+			return false, err
+		}
+		if record == nil || record.Status.Deployed {
+			continue
+		}
+		if deployed || newDeployFailure(&record.Status, tr.Status.CompletionTime.Time) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// awaitingNotified returns true if the notify PipelineRun succeeded but its ComponentBuild has not recorded the result
+// yet, it is kept so that the ComponentBuild controller can find it and the message is not posted again
+func (r *ReconcileArtifactBuild) awaitingNotified(ctx context.Context, pr *v1beta1.PipelineRun) bool {
+	if !pr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		return false
+	}
+	cb := v1alpha2.ComponentBuild{}
+	err := r.client.Get(ctx, types.NamespacedName{Namespace: pr.Namespace, Name: pr.Labels[NotifyPipelineLabel]}, &cb)
+	return err == nil && !cb.Status.ResultNotified
+}
+
+// pruneNamespaceRuns prunes the runs in the namespace after a run has finished, failures are only logged as the
+// next finished run will try again
+func (r *ReconcileArtifactBuild) pruneNamespaceRuns(ctx context.Context, log logr.Logger, namespace string) {
+	policy, err := r.retentionPolicy(ctx, log, namespace)
+	if err == nil {
+		err = r.pruneRuns(ctx, log, namespace, policy)
+	}
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Error pruning finished runs in namespace %s", namespace))
+	}
+}
+
+// deleteOldest deletes all but the newest limit runs, ordered by completion time
+func (r *ReconcileArtifactBuild) deleteOldest(ctx context.Context, log logr.Logger, runs []client.Object, completed map[client.Object]*metav1.Time, limit int) error {
+	if len(runs) <= limit {
+		return nil
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		a, b := completed[runs[i]], completed[runs[j]]
+		if a.Equal(b) {
+			return runs[i].GetName() > runs[j].GetName()
+		}
+		return b.Before(a)
+	})
+	for _, run := range runs[limit:] {
+		log.Info("Deleting finished run beyond the number kept", "name", run.GetName(), "completed", completed[run])
+		err := r.client.Delete(ctx, run, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,201 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	ctrl "sigs.k8s.io/controller-runtime"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// setNamespaceSettings changes the spec of the DeploymentTarget of the namespace. If it does not exist yet it is
+// created with the name and repository of the apheleia-config config map, so deploys are recorded under the same names.
+func setNamespaceSettings(g *WithT, client runtimeclient.Client, change func(spec *v1alpha2.DeploymentTargetSpec)) {
+	ctx := context.TODO()
+	target := v1alpha2.DeploymentTarget{}
+	err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ApheleiaConfig}, &target)
+	if errors.IsNotFound(err) {
+		target = v1alpha2.DeploymentTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: ApheleiaConfig, Namespace: namespace, Generation: 1},
+			Spec: v1alpha2.DeploymentTargetSpec{
+				RepositoryURL: DummyRepo,
+				CodeArtifact:  &v1alpha2.CodeArtifactSettings{Domain: DummyDomain, Owner: DummyOwner},
+			},
+		}
+		meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{Type: v1alpha2.ConditionUsable, Status: metav1.ConditionTrue, Reason: v1alpha2.ReasonTargetValid, ObservedGeneration: 1})
+		change(&target.Spec)
+		g.Expect(client.Create(ctx, &target)).NotTo(HaveOccurred())
+		return
+	}
+	g.Expect(err).NotTo(HaveOccurred())
+	change(&target.Spec)
+	g.Expect(client.Update(ctx, &target)).NotTo(HaveOccurred())
+}
+
+func TestComponentBuildTTL(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retention = &v1alpha2.RetentionSettings{ComponentBuildTTL: &metav1.Duration{Duration: time.Hour}}
+	})
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	//the artifact fails to build, which finishes the ComponentBuild
+	abr := jbs.ArtifactBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(artifact)}, &abr)).NotTo(HaveOccurred())
+	abr.Status.State = jbs.ArtifactBuildStateFailed
+	g.Expect(client.Status().Update(ctx, &abr)).NotTo(HaveOccurred())
+	result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeNumerically(">", 59*time.Minute))
+	g.Expect(result.RequeueAfter).To(BeNumerically("<=", time.Hour))
+
+	//once the TTL has passed it is deleted
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateFailed))
+	ready := meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionReady)
+	ready.LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	g.Expect(client.Status().Update(ctx, &cb)).NotTo(HaveOccurred())
+	result, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result.RequeueAfter).To(BeZero())
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.DeletionTimestamp).NotTo(BeNil())
+
+	//the finalizer releases the ArtifactBuilds and the deletion completes
+	_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(errors.IsNotFound(client.Get(ctx, cbName, &cb))).To(BeTrue())
+}
+
+func TestComponentBuildTTLKeep(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retention = &v1alpha2.RetentionSettings{ComponentBuildTTL: &metav1.Duration{Duration: time.Hour}}
+	})
+	cb := defaultComponentBuild()
+	cb.Annotations = map[string]string{KeepAnnotation: "true"}
+	cb.Status.State = v1alpha2.ComponentBuildStateComplete
+	setCondition(&cb, v1alpha2.ConditionReady, metav1.ConditionTrue, v1alpha2.ReasonComplete, "")
+	cb.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+
+	policy, err := reconciler.retentionPolicy(ctx, ctrl.Log, namespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(policy.ttl).To(Equal(time.Hour))
+	expiry, err := reconciler.expireComponentBuild(ctx, ctrl.Log, &cb, policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiry).To(BeZero())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.DeletionTimestamp).To(BeNil())
+
+	//without the annotation a build whose result has not been reported yet is kept as well
+	cb.Annotations = nil
+	cb.Spec.PRURL = "https://github.com/test/test/pull/1"
+	expiry, err = reconciler.expireComponentBuild(ctx, ctrl.Log, &cb, policy)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(expiry).To(BeZero())
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &cb)).NotTo(HaveOccurred())
+}
+
+func TestPruneRunsBeforeResultRecorded(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	keepRuns := 0
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retention = &v1alpha2.RetentionSettings{KeepRuns: &keepRuns}
+	})
+	abrName := createBuiltArtifact(g, client, artifact, time.Now())
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(trl.Items).To(HaveLen(1))
+	tr := trl.Items[0]
+	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	tr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue})
+	g.Expect(client.Status().Update(ctx, &tr)).NotTo(HaveOccurred())
+
+	//another run finishing prunes before this TaskRun is reconciled, it is kept as its result has not been recorded
+	reconciler.pruneNamespaceRuns(ctx, ctrl.Log, namespace)
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: tr.Name}, &tr)).NotTo(HaveOccurred())
+
+	//once the result is recorded it is pruned
+	g.Expect(reconcileTaskRun(ctx, client, reconciler, types.NamespacedName{Namespace: namespace, Name: tr.Name})).NotTo(HaveOccurred())
+	record := v1alpha2.DeploymentRecord{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: deploymentRecordName(abrName, ApheleiaConfig)}, &record)).NotTo(HaveOccurred())
+	g.Expect(record.Status.Deployed).To(BeTrue())
+	g.Expect(record.Status.TaskRun).To(Equal(tr.Name))
+	g.Expect(errors.IsNotFound(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: tr.Name}, &tr))).To(BeTrue())
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.GetArtifactState(artifact).Deployed).To(BeTrue())
+}
+
+func TestPruneRuns(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	keepRuns := 2
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retention = &v1alpha2.RetentionSettings{KeepRuns: &keepRuns}
+	})
+	now := time.Now()
+	for i := 0; i < 4; i++ {
+		tr := v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("deploy-%d", i), Namespace: namespace, Labels: map[string]string{DeployTaskLabel: "test"}}}
+		tr.Status.CompletionTime = &metav1.Time{Time: now.Add(time.Duration(i) * time.Minute)}
+		pr := v1beta1.PipelineRun{ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("notify-%d", i), Namespace: namespace, Labels: map[string]string{NotifyPipelineLabel: "test"}}}
+		pr.Status.CompletionTime = &metav1.Time{Time: now.Add(time.Duration(i) * time.Minute)}
+		if i == 0 {
+			tr.Annotations = map[string]string{KeepAnnotation: "true"}
+		}
+		g.Expect(client.Create(ctx, &tr)).NotTo(HaveOccurred())
+		g.Expect(client.Create(ctx, &pr)).NotTo(HaveOccurred())
+	}
+	running := v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "deploy-running", Namespace: namespace, Labels: map[string]string{DeployTaskLabel: "test"}}}
+	g.Expect(client.Create(ctx, &running)).NotTo(HaveOccurred())
+	unrelated := v1beta1.TaskRun{ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: namespace}}
+	unrelated.Status.CompletionTime = &metav1.Time{Time: now}
+	g.Expect(client.Create(ctx, &unrelated)).NotTo(HaveOccurred())
+
+	reconciler.pruneNamespaceRuns(ctx, ctrl.Log, namespace)
+
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	var taskRuns []string
+	for _, i := range trl.Items {
+		taskRuns = append(taskRuns, i.Name)
+	}
+	g.Expect(taskRuns).To(ConsistOf("deploy-0", "deploy-2", "deploy-3", "deploy-running", "build"))
+	prl := v1beta1.PipelineRunList{}
+	g.Expect(client.List(ctx, &prl)).NotTo(HaveOccurred())
+	var pipelineRuns []string
+	for _, i := range prl.Items {
+		pipelineRuns = append(pipelineRuns, i.Name)
+	}
+	g.Expect(pipelineRuns).To(ConsistOf("notify-2", "notify-3"))
+}
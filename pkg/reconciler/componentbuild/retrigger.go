@@ -0,0 +1,191 @@
+package componentbuild
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// RetriggerPipelineLabel is set on the PipelineRuns that are created to retrigger a build, with the name of the
+	// ComponentBuild
+	RetriggerPipelineLabel = "apheleia.io/retrigger-pipeline"
+)
+
+// retrigger triggers a new build of the component once the ComponentBuild has completed successfully, and records
+// the result in the status so that it is only triggered once per completion. The attempt is claimed in the status
+// before the trigger fires, so a reconcile that read a stale copy of the ComponentBuild cannot fire it again. A failed
+// trigger is retried with a backoff, the time until the next attempt is returned.
+func (r *ReconcileArtifactBuild) retrigger(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, original *v1alpha2.ComponentBuild) (time.Duration, error) {
+	if cb.Status.State != v1alpha2.ComponentBuildStateComplete {
+		if cb.Status.State == v1alpha2.ComponentBuildStateInProgress {
+			//the build has to complete again before it is retriggered again
+			cb.Status.Retrigger = nil
+		}
+		return 0, nil
+	}
+	if cb.Status.Retrigger != nil && cb.Status.Retrigger.Triggered {
+		return 0, nil
+	}
+	settings, err := r.namespaceSettings(ctx, cb.Namespace)
+	if err != nil {
+		return 0, err
+	}
+	target := settings.GetRetriggerTarget(cb.Spec.RetriggerTarget)
+	if target == nil {
+		if cb.Spec.RetriggerTarget != "" {
+			//the ComponentBuild is reconciled again when the DeploymentTarget changes
+			msg := fmt.Sprintf("retrigger target %q is not defined in the DeploymentTarget", cb.Spec.RetriggerTarget)
+			if cb.Status.Retrigger == nil || cb.Status.Retrigger.LastFailure != msg {
+				log.Info("Unknown retrigger target", "name", cb.Name, "retriggerTarget", cb.Spec.RetriggerTarget)
+				r.eventRecorder.Eventf(cb, v1.EventTypeWarning, "RetriggerFailed", "Failed to trigger a new build: %s", msg)
+			}
+			cb.Status.Retrigger = &v1alpha2.RetriggerStatus{LastFailure: msg}
+		}
+		return 0, nil
+	}
+	status := cb.Status.Retrigger
+	if status == nil || status.Type != target.Type {
+		status = &v1alpha2.RetriggerStatus{Type: target.Type}
+		cb.Status.Retrigger = status
+	}
+	if status.Triggering {
+		now := metav1.Now()
+		log.Info("The result of triggering the build was not recorded, it is not triggered again", "name", cb.Name, "type", target.Type)
+		r.eventRecorder.Eventf(cb, v1.EventTypeWarning, "RetriggerUnknown", "A new build was triggered with %s, but its result was not recorded", target.Type)
+		status.Triggering = false
+		status.Triggered = true
+		status.TriggeredTime = &now
+		return 0, nil
+	}
+	if status.LastAttemptTime != nil {
+		if wait := time.Until(status.LastAttemptTime.Add(backoff(status.Attempts))); wait > 0 {
+			return wait, nil
+		}
+	}
+	claim := status.DeepCopy()
+	claim.Triggering = true
+	err = r.recordStatus(ctx, cb, original, func(s *v1alpha2.ComponentBuildStatus) {
+		s.Retrigger = claim.DeepCopy()
+	})
+	if errors.IsConflict(err) {
+		//the ComponentBuild is reconciled again once the status has been written
+		log.Info("ComponentBuild changed before the build was triggered, it will be triggered by the next reconcile", "name", cb.Name)
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	status = cb.Status.Retrigger
+	triggered, err := r.fireRetrigger(ctx, log, cb, settings.GetNotification(), target)
+	now := metav1.Now()
+	status.Triggering = false
+	if err != nil {
+		status.Attempts++
+		status.LastFailure = err.Error()
+		status.LastAttemptTime = &now
+		log.Error(err, "Error retriggering the build of the ComponentBuild", "name", cb.Name, "type", target.Type, "attempts", status.Attempts)
+		r.eventRecorder.Eventf(cb, v1.EventTypeWarning, "RetriggerFailed", "Failed to trigger a new build with %s: %s", target.Type, err.Error())
+		return backoff(status.Attempts), nil
+	}
+	status.Triggered = true
+	status.Target = triggered
+	status.TriggeredTime = &now
+	status.LastFailure = ""
+	r.eventRecorder.Eventf(cb, v1.EventTypeNormal, "Retriggered", "Triggered a new build with %s: %s", target.Type, triggered)
+	return 0, nil
+}
+
+// fireRetrigger triggers the build and returns the URL that was called or the name of the PipelineRun
+func (r *ReconcileArtifactBuild) fireRetrigger(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, notification v1alpha2.NotificationSettings, target *v1alpha2.RetriggerTarget) (string, error) {
+	var secret *v1.Secret
+	if target.Secret != "" {
+		var err error
+		secret, err = r.secret(ctx, cb.Namespace, target.Secret)
+		if err != nil {
+			return "", err
+		}
+	}
+	switch target.Type {
+	case v1alpha2.RetriggerJenkins:
+		log.Info("Retriggering the build via Jenkins", "name", cb.Name, "url", target.URL)
+		return target.URL, triggerJenkins(ctx, notifierClient, target.URL, cb, secret)
+	case v1alpha2.RetriggerWebhook:
+		log.Info("Retriggering the build via webhook", "name", cb.Name, "url", target.URL)
+		message, _ := notificationMessage(notification, cb)
+		notifier := &webhookNotifier{client: notifierClient, url: target.URL}
+		if secret != nil {
+			notifier.token = string(secret.Data[v1alpha2.RetriggerTokenKey])
+		}
+		_, err := notifier.Notify(ctx, log, cb, message)
+		return target.URL, err
+	case v1alpha2.RetriggerTekton:
+		pr, err := retriggerPipelineRun(cb, target.PipelineRunTemplate)
+		if err != nil {
+			return "", err
+		}
+		log.Info("Retriggering the build via Tekton", "name", cb.Name, "pipelineRun", pr.Name, "generateName", pr.GenerateName)
+		if err := r.client.Create(ctx, pr); err != nil {
+			return "", err
+		}
+		return pr.Name, nil
+	}
+	return "", fmt.Errorf("unknown retrigger type %q", target.Type)
+}
+
+// triggerJenkins calls a Jenkins remote build trigger URL, passing the build token from the secret and the cause of
+// the build. Requests are authenticated with the user and API token from the secret if it has them.
+func triggerJenkins(ctx context.Context, client *http.Client, target string, cb *v1alpha2.ComponentBuild, secret *v1.Secret) error {
+	u, err := url.Parse(target)
+	if err != nil {
+		return err
+	}
+	query := u.Query()
+	query.Set("cause", fmt.Sprintf("Apheleia rebuilt and deployed all dependencies of %s %s", cb.Spec.SCMURL, cb.Spec.Tag))
+	header := http.Header{}
+	if secret != nil {
+		if token := string(secret.Data[v1alpha2.RetriggerTokenKey]); token != "" {
+			query.Set("token", token)
+		}
+		if username := string(secret.Data[v1alpha2.RetriggerUsernameKey]); username != "" {
+			credentials := username + ":" + string(secret.Data[v1alpha2.RetriggerPasswordKey])
+			header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(credentials)))
+		}
+	}
+	u.RawQuery = query.Encode()
+	_, err = apiRequest(ctx, client, http.MethodPost, u.String(), header, nil)
+	return err
+}
+
+// retriggerPipelineRun renders the PipelineRun template for the ComponentBuild
+func retriggerPipelineRun(cb *v1alpha2.ComponentBuild, text string) (*v1beta1.PipelineRun, error) {
+	rendered, err := renderTemplate("pipelineRunTemplate", text, newNotificationData(cb))
+	if err != nil {
+		return nil, fmt.Errorf("invalid pipelineRunTemplate: %s", err.Error())
+	}
+	pr := &v1beta1.PipelineRun{}
+	if err := yaml.UnmarshalStrict([]byte(rendered), pr); err != nil {
+		return nil, fmt.Errorf("invalid pipelineRunTemplate: %s", err.Error())
+	}
+	//the PipelineRun is not owned by the ComponentBuild, so that the build is not cancelled if it is deleted
+	pr.Namespace = cb.Namespace
+	if pr.Name == "" && pr.GenerateName == "" {
+		pr.GenerateName = cb.Name + "-retrigger-"
+	}
+	if pr.Labels == nil {
+		pr.Labels = map[string]string{}
+	}
+	pr.Labels[RetriggerPipelineLabel] = cb.Name
+	return pr, nil
+}
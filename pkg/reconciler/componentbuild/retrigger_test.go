@@ -0,0 +1,199 @@
+package componentbuild
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func completedComponentBuild() *v1alpha2.ComponentBuild {
+	cb := defaultComponentBuild()
+	cb.Status.State = v1alpha2.ComponentBuildStateComplete
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, Built: true, Deployed: true}}
+	return &cb
+}
+
+func TestRetriggerJenkins(t *testing.T) {
+	g := NewGomegaWithT(t)
+	jenkins := &notificationServer{}
+	server := httptest.NewServer(jenkins)
+	defer server.Close()
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	secret := v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "jenkins", Namespace: namespace}, Data: map[string][]byte{
+		v1alpha2.RetriggerTokenKey:    []byte("build-token"),
+		v1alpha2.RetriggerUsernameKey: []byte("apheleia"),
+		v1alpha2.RetriggerPasswordKey: []byte("api-token"),
+	}}
+	g.Expect(client.Create(ctx, &secret)).NotTo(HaveOccurred())
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.RetriggerTargets = []v1alpha2.NamedRetriggerTarget{{Name: "jenkins", RetriggerTarget: v1alpha2.RetriggerTarget{Type: v1alpha2.RetriggerJenkins, URL: server.URL + "/job/test/build", Secret: "jenkins"}}}
+	})
+	cb := completedComponentBuild()
+	cb.Spec.RetriggerTarget = "jenkins"
+	g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+	original := cb.DeepCopy()
+
+	retry, err := reconciler.retrigger(ctx, ctrl.Log, cb, original)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retry).To(BeZero())
+	g.Expect(jenkins.method).To(Equal(http.MethodPost))
+	g.Expect(jenkins.path).To(Equal("/job/test/build"))
+	g.Expect(jenkins.header.Get("Authorization")).To(Equal("Basic YXBoZWxlaWE6YXBpLXRva2Vu"))
+	g.Expect(cb.Status.Retrigger.Triggered).To(BeTrue())
+	g.Expect(cb.Status.Retrigger.Triggering).To(BeFalse())
+	g.Expect(cb.Status.Retrigger.Target).To(Equal(server.URL + "/job/test/build"))
+
+	//it is only triggered once per completion
+	_, err = reconciler.retrigger(ctx, ctrl.Log, cb, original)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(jenkins.requests).To(Equal(1))
+
+	//once the build is back in progress it is triggered again when it completes
+	cb.Status.State = v1alpha2.ComponentBuildStateInProgress
+	_, err = reconciler.retrigger(ctx, ctrl.Log, cb, original)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Retrigger).To(BeNil())
+	cb.Status.State = v1alpha2.ComponentBuildStateComplete
+
+	//failures are recorded and retried with a backoff
+	jenkins.status = http.StatusForbidden
+	retry, err = reconciler.retrigger(ctx, ctrl.Log, cb, original)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retry).To(Equal(deployBackoffBase))
+	g.Expect(cb.Status.Retrigger.Triggered).To(BeFalse())
+	g.Expect(cb.Status.Retrigger.Attempts).To(Equal(1))
+	g.Expect(cb.Status.Retrigger.LastFailure).To(ContainSubstring("403"))
+	_, err = reconciler.retrigger(ctx, ctrl.Log, cb, original)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(jenkins.requests).To(Equal(2))
+}
+
+func TestTriggerJenkinsQuery(t *testing.T) {
+	g := NewGomegaWithT(t)
+	var query map[string][]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.Query()
+	}))
+	defer server.Close()
+	secret := &v1.Secret{Data: map[string][]byte{v1alpha2.RetriggerTokenKey: []byte("build-token")}}
+	g.Expect(triggerJenkins(context.TODO(), server.Client(), server.URL+"/job/test/buildWithParameters?BRANCH=main", completedComponentBuild(), secret)).To(Succeed())
+	g.Expect(query).To(HaveKeyWithValue("token", []string{"build-token"}))
+	g.Expect(query).To(HaveKeyWithValue("BRANCH", []string{"main"}))
+	g.Expect(query).To(HaveKeyWithValue("cause", []string{"Apheleia rebuilt and deployed all dependencies of https://test.com/test.git 1.0"}))
+}
+
+func TestRetriggerTekton(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retrigger = &v1alpha2.RetriggerTarget{Type: v1alpha2.RetriggerTekton, PipelineRunTemplate: `
+metadata:
+  generateName: build-
+spec:
+  pipelineRef:
+    name: build
+  params:
+  - name: revision
+    value: "{{ .ComponentBuild.Spec.Tag }}"
+`}
+	})
+	cb := completedComponentBuild()
+	g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+	_, err := reconciler.retrigger(ctx, ctrl.Log, cb, cb.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Retrigger.Triggered).To(BeTrue())
+	prl := v1beta1.PipelineRunList{}
+	g.Expect(client.List(ctx, &prl)).NotTo(HaveOccurred())
+	g.Expect(prl.Items).To(HaveLen(1))
+	pr := prl.Items[0]
+	g.Expect(pr.Name).To(Equal(cb.Status.Retrigger.Target))
+	g.Expect(pr.Labels).To(HaveKeyWithValue(RetriggerPipelineLabel, name))
+	g.Expect(pr.OwnerReferences).To(BeEmpty())
+	g.Expect(pr.Spec.PipelineRef.Name).To(Equal("build"))
+	g.Expect(pr.Spec.Params[0].Value.StringVal).To(Equal("1.0"))
+
+	//a build that failed is not retriggered
+	failed := failedComponentBuild("")
+	_, err = reconciler.retrigger(ctx, ctrl.Log, failed, failed.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(failed.Status.Retrigger).To(BeNil())
+}
+
+func TestRetriggerOnce(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retrigger = &v1alpha2.RetriggerTarget{Type: v1alpha2.RetriggerTekton, PipelineRunTemplate: "spec:\n  pipelineRef:\n    name: build\n"}
+	})
+	cb := completedComponentBuild()
+	g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+	stale := cb.DeepCopy()
+	pipelineRuns := func() int {
+		prl := v1beta1.PipelineRunList{}
+		g.Expect(client.List(ctx, &prl)).NotTo(HaveOccurred())
+		return len(prl.Items)
+	}
+
+	//the attempt is claimed before the PipelineRun is created
+	_, err := reconciler.retrigger(ctx, ctrl.Log, cb, cb.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pipelineRuns()).To(Equal(1))
+	latest := v1alpha2.ComponentBuild{}
+	g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &latest)).NotTo(HaveOccurred())
+	g.Expect(latest.Status.Retrigger.Triggering).To(BeTrue())
+
+	//a reconcile of a copy read before the claim cannot claim it again
+	_, err = reconciler.retrigger(ctx, ctrl.Log, stale, stale.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pipelineRuns()).To(Equal(1))
+	g.Expect(stale.Status.Retrigger.Triggering).To(BeFalse())
+
+	//the result was not recorded, so it is not known whether the build was triggered and it is not triggered again
+	_, err = reconciler.retrigger(ctx, ctrl.Log, &latest, latest.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(pipelineRuns()).To(Equal(1))
+	g.Expect(latest.Status.Retrigger.Triggered).To(BeTrue())
+	g.Expect(latest.Status.Retrigger.Triggering).To(BeFalse())
+}
+
+func TestRetriggerUnknownTarget(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retrigger = &v1alpha2.RetriggerTarget{Type: v1alpha2.RetriggerTekton, PipelineRunTemplate: "spec:\n  pipelineRef:\n    name: build\n"}
+	})
+	cb := completedComponentBuild()
+	cb.Spec.RetriggerTarget = "missing"
+	g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+
+	//a target that the DeploymentTarget does not define is reported, the default target is not used instead
+	retry, err := reconciler.retrigger(ctx, ctrl.Log, cb, cb.DeepCopy())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(retry).To(BeZero())
+	g.Expect(cb.Status.Retrigger.Triggered).To(BeFalse())
+	g.Expect(cb.Status.Retrigger.LastFailure).To(ContainSubstring(`"missing"`))
+	prl := v1beta1.PipelineRunList{}
+	g.Expect(client.List(ctx, &prl)).NotTo(HaveOccurred())
+	g.Expect(prl.Items).To(BeEmpty())
+}
+
+func TestRetriggerPipelineRunInvalid(t *testing.T) {
+	g := NewGomegaWithT(t)
+	_, err := retriggerPipelineRun(completedComponentBuild(), "spec:\n  unknownField: true\n")
+	g.Expect(err).To(HaveOccurred())
+	_, err = retriggerPipelineRun(completedComponentBuild(), "{{ .Missing }}")
+	g.Expect(err).To(HaveOccurred())
+}
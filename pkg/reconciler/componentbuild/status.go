@@ -0,0 +1,113 @@
+package componentbuild
+
+import (
+	"context"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/go-logr/logr"
+	jvmbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// patchStatus writes the status of the ComponentBuild as a merge patch against the copy it was read as. The patch
+// carries the resource version, so if the ComponentBuild was changed in the meantime it fails with a conflict rather
+// than overwriting the other change.
+func (r *ReconcileArtifactBuild) patchStatus(ctx context.Context, cb *v1alpha2.ComponentBuild, original *v1alpha2.ComponentBuild) error {
+	return r.client.Status().Patch(ctx, cb, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// patchFinalizers writes the finalizers of the ComponentBuild as a merge patch against the copy it was read as, with
+// the same conflict detection as patchStatus
+func (r *ReconcileArtifactBuild) patchFinalizers(ctx context.Context, cb *v1alpha2.ComponentBuild, original *v1alpha2.ComponentBuild) error {
+	return r.client.Patch(ctx, cb, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{}))
+}
+
+// recordStatus writes a change to the status straight away, before a side effect that must not be repeated. The patch
+// carries the resource version that was read, so it fails with a conflict if the ComponentBuild has changed or was
+// read from a cache that had not caught up yet, rather than letting the side effect happen twice. The change is
+// applied to both cb and original, so the status that is written at the end of the reconcile builds on it.
+func (r *ReconcileArtifactBuild) recordStatus(ctx context.Context, cb *v1alpha2.ComponentBuild, original *v1alpha2.ComponentBuild, change func(*v1alpha2.ComponentBuildStatus)) error {
+	updated := original.DeepCopy()
+	change(&updated.Status)
+	if err := r.patchStatus(ctx, updated, original); err != nil {
+		return err
+	}
+	updated.DeepCopyInto(original)
+	change(&cb.Status)
+	cb.ResourceVersion = original.ResourceVersion
+	return nil
+}
+
+// writeStatus writes the status computed by handleComponentBuildReceived. If the ComponentBuild changed since it was
+// read, the state of its artifacts is computed again from the latest version, which is read from the API server as
+// the cache may not have caught up yet. Only the status is computed again: what this reconcile deployed, reported and
+// triggered is kept as it is recorded in cb, so a conflict never repeats any of it.
+func (r *ReconcileArtifactBuild) writeStatus(ctx context.Context, log logr.Logger, cb *v1alpha2.ComponentBuild, original *v1alpha2.ComponentBuild, target *v1alpha2.DeploymentTarget) error {
+	err := r.patchStatus(ctx, cb, original)
+	if !errors.IsConflict(err) {
+		return err
+	}
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		latest := v1alpha2.ComponentBuild{}
+		if err := r.apiReader.Get(ctx, client.ObjectKeyFromObject(cb), &latest); err != nil {
+			return err
+		}
+		updated := latest.DeepCopy()
+		if err := r.refreshStatus(ctx, log, updated, cb, target); err != nil {
+			return err
+		}
+		if err := r.patchStatus(ctx, updated, &latest); err != nil {
+			return err
+		}
+		updated.DeepCopyInto(cb)
+		return nil
+	})
+}
+
+// refreshStatus sets the status of the latest version of the ComponentBuild to the one computed by the reconcile,
+// with the state of the artifacts read again. The deploys of this reconcile are not visible in the cache yet, so the
+// deploy state that was computed is kept for artifacts that are not deployed, and the transitions that only show up
+// now are queued as events.
+func (r *ReconcileArtifactBuild) refreshStatus(ctx context.Context, log logr.Logger, latest *v1alpha2.ComponentBuild, computed *v1alpha2.ComponentBuild, target *v1alpha2.DeploymentTarget) error {
+	latest.Status = *computed.Status.DeepCopy()
+	latest.Status.ArtifactState = nil
+	latest.Status.Deploying = 0
+	latest.Status.Queued = 0
+	for _, a := range latest.Spec.Artifacts {
+		gav := a.GAV()
+		abr := jvmbs.ArtifactBuild{}
+		key := types.NamespacedName{Namespace: latest.Namespace, Name: artifactbuild.CreateABRName(gav)}
+		err := r.client.Get(ctx, key, &abr)
+		if errors.IsNotFound(err) {
+			//artifacts that were added to the spec are referenced by the next reconcile
+			abr.Name = key.Name
+			abr.Namespace = key.Namespace
+		} else if err != nil {
+			return err
+		}
+		state := r.artifactState(ctx, log, gav, &abr, target)
+		if previous := computed.Status.GetArtifactState(gav); previous != nil && state.Built && !state.Deployed {
+			state.Deployed = previous.Deployed
+			state.Deploying = previous.Deploying
+			state.Queued = previous.Queued
+			state.DeployAttempts = previous.DeployAttempts
+			state.LastDeployFailure = previous.LastDeployFailure
+			state.DeployFailed = previous.DeployFailed
+		}
+		if state.Deploying {
+			latest.Status.Deploying++
+		}
+		if state.Queued {
+			latest.Status.Queued++
+		}
+		latest.Status.SetArtifactState(state)
+	}
+	updateState(latest)
+	queueEvents(latest, &computed.Status)
+	updateConditions(latest)
+	return nil
+}
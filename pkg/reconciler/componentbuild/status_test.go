@@ -0,0 +1,143 @@
+package componentbuild
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	jbs "github.com/redhat-appstudio/jvm-build-service/pkg/apis/jvmbuildservice/v1alpha1"
+	"github.com/redhat-appstudio/jvm-build-service/pkg/reconciler/artifactbuild"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"knative.dev/pkg/apis"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// interleavingClient runs a function just before the next status patch, standing in for another reconcile of the
+// same ComponentBuild that finishes first
+type interleavingClient struct {
+	runtimeclient.Client
+	before func()
+	// skip is the number of status patches that are let through before the function runs
+	skip int
+	// patches is the number of status patches that were attempted
+	patches int
+}
+
+func (c *interleavingClient) Status() runtimeclient.StatusWriter {
+	return &interleavingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+type interleavingStatusWriter struct {
+	runtimeclient.StatusWriter
+	client *interleavingClient
+}
+
+func (w *interleavingStatusWriter) Patch(ctx context.Context, obj runtimeclient.Object, patch runtimeclient.Patch, opts ...runtimeclient.PatchOption) error {
+	w.client.patches++
+	if before := w.client.before; before != nil && w.client.patches > w.client.skip {
+		w.client.before = nil
+		before()
+	}
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func completeArtifactBuild(g *WithT, client runtimeclient.Client, gav string) {
+	abr := jbs.ArtifactBuild{}
+	g.Expect(client.Get(context.TODO(), types.NamespacedName{Namespace: namespace, Name: artifactbuild.CreateABRName(gav)}, &abr)).NotTo(HaveOccurred())
+	abr.Status.State = jbs.ArtifactBuildStateComplete
+	g.Expect(client.Status().Update(context.TODO(), &abr)).NotTo(HaveOccurred())
+}
+
+func TestConcurrentArtifactBuildCompletions(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	const other = "com.test:other:1.0"
+	cb := defaultComponentBuild()
+	cb.Spec.Artifacts = append(cb.Spec.Artifacts, v1alpha2.ParseGAV(other))
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	//the first artifact completes and a reconcile starts, the second completes and is reconciled before the first
+	//reconcile writes its status, which was computed without the second artifact
+	completeArtifactBuild(g, client, artifact)
+	interleaving := &interleavingClient{Client: client}
+	racing := *reconciler
+	racing.client = interleaving
+	interleaving.before = func() {
+		completeArtifactBuild(g, client, other)
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+	_, err = racing.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	//the stale status was rejected and computed again, rather than overwriting the other reconcile
+	g.Expect(interleaving.patches).To(Equal(2))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.GetArtifactState(artifact).Built).To(BeTrue())
+	g.Expect(cb.Status.GetArtifactState(other).Built).To(BeTrue())
+}
+
+func TestConflictDoesNotRepeatSideEffects(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	createBuiltArtifact(g, client, artifact, time.Now())
+	cb := defaultComponentBuild()
+	g.Expect(client.Create(ctx, &cb)).NotTo(HaveOccurred())
+	cbName := types.NamespacedName{Namespace: namespace, Name: name}
+	//the ComponentBuild is edited while each reconcile runs, so every status patch conflicts once
+	racingReconcile := func(skip int) {
+		interleaving := &interleavingClient{Client: client, skip: skip}
+		racing := *reconciler
+		racing.client = interleaving
+		interleaving.before = func() {
+			latest := v1alpha2.ComponentBuild{}
+			g.Expect(client.Get(ctx, cbName, &latest)).NotTo(HaveOccurred())
+			latest.Annotations = map[string]string{"edited": time.Now().String()}
+			g.Expect(client.Update(ctx, &latest)).NotTo(HaveOccurred())
+		}
+		_, err := racing.Reconcile(ctx, reconcile.Request{NamespacedName: cbName})
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(interleaving.patches).To(Equal(skip + 2))
+	}
+
+	racingReconcile(0)
+	trl := v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(trl.Items).To(HaveLen(1))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.GetArtifactState(artifact).Deploying).To(BeTrue())
+
+	tr := trl.Items[0]
+	tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+	tr.Status.SetCondition(&apis.Condition{Type: apis.ConditionSucceeded, Status: v1.ConditionTrue})
+	g.Expect(client.Status().Update(ctx, &tr)).NotTo(HaveOccurred())
+	g.Expect(reconcileTaskRun(ctx, client, reconciler, types.NamespacedName{Namespace: namespace, Name: tr.Name})).NotTo(HaveOccurred())
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.State).To(Equal(v1alpha2.ComponentBuildStateComplete))
+
+	//the build is retriggered once, the attempt is claimed with the first status patch and recorded with the second
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Retrigger = &v1alpha2.RetriggerTarget{Type: v1alpha2.RetriggerTekton, PipelineRunTemplate: "spec:\n  pipelineRef:\n    name: build\n"}
+	})
+	racingReconcile(1)
+	prl := v1beta1.PipelineRunList{}
+	g.Expect(client.List(ctx, &prl, runtimeclient.HasLabels{RetriggerPipelineLabel})).NotTo(HaveOccurred())
+	g.Expect(prl.Items).To(HaveLen(1))
+	g.Expect(client.Get(ctx, cbName, &cb)).NotTo(HaveOccurred())
+	g.Expect(cb.Status.Retrigger.Triggered).To(BeTrue())
+	g.Expect(cb.Status.Retrigger.Target).To(Equal(prl.Items[0].Name))
+	trl = v1beta1.TaskRunList{}
+	g.Expect(client.List(ctx, &trl)).NotTo(HaveOccurred())
+	g.Expect(trl.Items).To(HaveLen(1))
+}
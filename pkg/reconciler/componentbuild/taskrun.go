@@ -0,0 +1,153 @@
+package componentbuild
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"knative.dev/pkg/apis"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReconcileTaskRun records the results of finished deploy TaskRuns in the DeploymentRecords of the artifacts. The
+// ComponentBuilds that reference the artifacts are reconciled when their records change.
+type ReconcileTaskRun struct {
+	*ReconcileArtifactBuild
+}
+
+func (r *ReconcileTaskRun) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, r.config.Get().ReconcileTimeout.Duration)
+	defer cancel()
+	log := ctrl.Log.WithName("taskrun").WithValues("request", request.NamespacedName)
+
+	tr := v1beta1.TaskRun{}
+	err := r.client.Get(ctx, request.NamespacedName, &tr)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		log.Error(err, "Reconcile key %s as taskrun unexpected error", request.NamespacedName.String())
+		return reconcile.Result{}, err
+	}
+	return r.handleTaskRunReceived(ctx, log, &tr)
+}
+
+func (r *ReconcileTaskRun) handleTaskRunReceived(ctx context.Context, log logr.Logger, tr *v1beta1.TaskRun) (reconcile.Result, error) {
+	if tr.Status.CompletionTime == nil || !isDeployTaskRun(tr) {
+		return reconcile.Result{}, nil
+	}
+	log.Info("Handling TaskRun", "name", tr.Name)
+	defer r.pruneNamespaceRuns(ctx, log, tr.Namespace)
+	if tr.Labels[BatchDeployTaskLabel] != "" {
+		return r.handleBatchTaskRunReceived(ctx, log, tr)
+	}
+	//TaskRuns started before deployments were recorded have no record, the artifact will be deployed again
+	record, err := r.getDeploymentRecord(ctx, tr.Namespace, deployTaskRecord(tr))
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if record == nil {
+		msg := "deploy taskrun %s:%s has no deployment record"
+		r.eventRecorder.Eventf(tr, v1.EventTypeWarning, "MissingDeploymentRecord", msg, tr.Namespace, tr.Name)
+		log.Info(fmt.Sprintf(msg, tr.Namespace, tr.Name))
+		return reconcile.Result{}, nil
+	}
+	if record.Status.Deployed {
+		return reconcile.Result{}, nil
+	}
+	if tr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		err := r.markDeployed(ctx, record, taskRunParam(tr, "REPO"), tr.Name)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Error marking deployment record %s as deployed", record.Name))
+			return reconcile.Result{}, err
+		}
+		observeDeployTaskRun(tr, true)
+		return reconcile.Result{}, nil
+	}
+	failedAt, message := taskRunFailure(tr)
+	if newDeployFailure(&record.Status, failedAt) {
+		observeDeployTaskRun(tr, false)
+	}
+	err = r.recordDeployFailure(ctx, record, failedAt, message)
+	if err != nil {
+		log.Error(err, fmt.Sprintf("Error recording deploy failure on deployment record %s", record.Name))
+		return reconcile.Result{}, err
+	}
+	return reconcile.Result{}, nil
+}
+
+// handleBatchTaskRunReceived maps the result of a batched deploy back to the artifacts. The deploy task lists the
+// RebuiltArtifacts it deployed in its result, so artifacts are recorded as deployed even if the batch as a whole
+// failed.
+func (r *ReconcileTaskRun) handleBatchTaskRunReceived(ctx context.Context, log logr.Logger, tr *v1beta1.TaskRun) (reconcile.Result, error) {
+	//the TaskRun is only counted in the metrics the first time its result is recorded
+	recorded := false
+	var recordErr error
+	deployed := map[string]bool{}
+	for _, name := range strings.Split(taskRunResult(tr, DeployedResult), ",") {
+		deployed[strings.TrimSpace(name)] = true
+	}
+	for _, name := range batchArtifacts(tr) {
+		record, err := r.getDeploymentRecord(ctx, tr.Namespace, deploymentRecordName(name, tr.Annotations[DeploymentTargetAnnotation]))
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if record == nil || record.Status.Deployed {
+			continue
+		}
+		if !deployed[name] {
+			failedAt, message := taskRunFailure(tr)
+			recorded = recorded || newDeployFailure(&record.Status, failedAt)
+			err = r.recordDeployFailure(ctx, record, failedAt, message)
+			if err != nil {
+				log.Error(err, fmt.Sprintf("Error recording deploy failure on deployment record %s", record.Name))
+				recordErr = err
+			}
+			continue
+		}
+		err = r.markDeployed(ctx, record, taskRunParam(tr, "REPO"), tr.Name)
+		if err != nil {
+			log.Error(err, fmt.Sprintf("Error marking deployment record %s as deployed", record.Name))
+			recordErr = err
+		} else {
+			recorded = true
+		}
+	}
+	if recorded {
+		observeDeployTaskRun(tr, tr.Status.GetCondition(apis.ConditionSucceeded).IsTrue())
+	}
+	if !tr.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+		msg := "batched deploy taskrun %s:%s failed, artifacts that were not deployed will be retried with a backoff"
+		r.eventRecorder.Eventf(tr, v1.EventTypeWarning, "DeployFailed", msg, tr.Namespace, tr.Name)
+		log.Info(fmt.Sprintf(msg, tr.Namespace, tr.Name))
+	}
+	//records that could not be updated are retried, the ones that were are skipped as they are already deployed or
+	//the failure has already been counted
+	return reconcile.Result{}, recordErr
+}
+
+// taskRunResult returns the value of a string result of the TaskRun, or an empty string if it has no such result
+func taskRunResult(tr *v1beta1.TaskRun, name string) string {
+	for _, result := range tr.Status.TaskRunResults {
+		if result.Name == name {
+			return result.Value.StringVal
+		}
+	}
+	return ""
+}
+
+// taskRunParam returns the value of a string param of the TaskRun
+func taskRunParam(tr *v1beta1.TaskRun, name string) string {
+	for _, param := range tr.Spec.Params {
+		if param.Name == name {
+			return param.Value.StringVal
+		}
+	}
+	return ""
+}
@@ -0,0 +1,136 @@
+package componentbuild
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+)
+
+const (
+	// SuccessTemplate names the template for the message of a build that completed
+	SuccessTemplate = "successTemplate"
+	// FailureTemplate names the template for the message of a build that failed
+	FailureTemplate = "failureTemplate"
+	// ProgressTemplate names the template for the message of a build that is still in progress, which is only shown
+	// in progress comments
+	ProgressTemplate = "progressTemplate"
+)
+
+// defaultTemplates are used when the namespace does not configure a template, or configures an invalid one
+var defaultTemplates = map[string]string{
+	SuccessTemplate: "/retest Success all dependency builds have completed.",
+	FailureTemplate: `{{ if .Failed }}The following dependency builds have failed: {{ join .Failed ", " }}.{{ end }}` +
+		`{{ if and .Failed .DeployFailed }} {{ end }}` +
+		`{{ if .DeployFailed }}The following dependencies failed to deploy: {{ join .DeployFailed ", " }}.{{ end }}`,
+	ProgressTemplate: "Rebuilding dependencies, {{ .Outstanding }} of {{ .Total }} artifacts are outstanding.",
+}
+
+var templateFuncs = template.FuncMap{"join": strings.Join}
+
+// notificationData is what the notification templates are rendered with
+type notificationData struct {
+	// ComponentBuild is the build that is being reported
+	ComponentBuild *v1alpha2.ComponentBuild
+	// Artifacts holds the state of each artifact, including why its last deploy failed
+	Artifacts []notificationArtifact
+	// Total is the number of artifacts, of which Built have been rebuilt, Deployed have been deployed and
+	// Outstanding are neither done nor failed
+	Total       int
+	Built       int
+	Deployed    int
+	Outstanding int
+	// Failed are the GAVs of the artifacts that failed to build
+	Failed []string
+	// DeployFailed are the GAVs of the artifacts that failed to deploy
+	DeployFailed []string
+}
+
+// notificationArtifact is the state of an artifact as it is passed to the templates
+type notificationArtifact struct {
+	v1alpha2.ArtifactState
+	// Label describes the state, as it is shown in the progress comment
+	Label string
+}
+
+func newNotificationData(cb *v1alpha2.ComponentBuild) *notificationData {
+	data := &notificationData{ComponentBuild: cb, Total: len(cb.Status.ArtifactState), Outstanding: cb.Status.Outstanding}
+	for _, v := range cb.Status.ArtifactState {
+		data.Artifacts = append(data.Artifacts, notificationArtifact{ArtifactState: v, Label: artifactStateLabel(v)})
+		if v.Built {
+			data.Built++
+		}
+		if v.Done() {
+			data.Deployed++
+		}
+		if v.Failed {
+			data.Failed = append(data.Failed, v.GAV)
+		}
+		if v.DeployFailed {
+			data.DeployFailed = append(data.DeployFailed, v.GAV)
+		}
+	}
+	return data
+}
+
+// notificationMessage renders the message for the state of the ComponentBuild with the template from the notification
+// settings. If the template is invalid the default template is used instead. Problems with any of the configured
+// templates are returned along with the message, so they can be reported before the template is needed.
+func notificationMessage(notification v1alpha2.NotificationSettings, cb *v1alpha2.ComponentBuild) (string, error) {
+	config := map[string]string{
+		SuccessTemplate:  notification.SuccessTemplate,
+		FailureTemplate:  notification.FailureTemplate,
+		ProgressTemplate: notification.ProgressTemplate,
+	}
+	var problems []string
+	for _, key := range []string{SuccessTemplate, FailureTemplate, ProgressTemplate} {
+		if text := config[key]; text != "" {
+			if _, err := parseTemplate(key, text); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid %s: %s", key, err.Error()))
+			}
+		}
+	}
+	key := ProgressTemplate
+	switch cb.Status.State {
+	case v1alpha2.ComponentBuildStateComplete:
+		key = SuccessTemplate
+	case v1alpha2.ComponentBuildStateFailed:
+		key = FailureTemplate
+	}
+	data := newNotificationData(cb)
+	message, err := renderTemplate(key, defaultTemplates[key], data)
+	if err != nil {
+		return "", err
+	}
+	if text := config[key]; text != "" {
+		if tmpl, err := parseTemplate(key, text); err == nil {
+			configured := strings.Builder{}
+			if err := tmpl.Execute(&configured, data); err != nil {
+				problems = append(problems, fmt.Sprintf("invalid %s: %s", key, err.Error()))
+			} else {
+				message = configured.String()
+			}
+		}
+	}
+	if len(problems) > 0 {
+		return message, fmt.Errorf("%s in the DeploymentTarget", strings.Join(problems, ", "))
+	}
+	return message, nil
+}
+
+func parseTemplate(name string, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+}
+
+func renderTemplate(name string, text string, data *notificationData) (string, error) {
+	tmpl, err := parseTemplate(name, text)
+	if err != nil {
+		return "", err
+	}
+	message := strings.Builder{}
+	if err := tmpl.Execute(&message, data); err != nil {
+		return "", err
+	}
+	return message.String(), nil
+}
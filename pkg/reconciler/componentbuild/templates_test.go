@@ -0,0 +1,87 @@
+package componentbuild
+
+import (
+	"context"
+	"testing"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestDefaultNotificationMessages(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cb := defaultComponentBuild()
+	cb.Status.State = v1alpha2.ComponentBuildStateComplete
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, Built: true, Deployed: true}}
+	message, err := notificationMessage(v1alpha2.NotificationSettings{}, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("/retest Success all dependency builds have completed."))
+
+	cb.Status.State = v1alpha2.ComponentBuildStateFailed
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, Failed: true}, {GAV: "com.test:a:1.0", DeployFailed: true}, {GAV: "com.test:b:1.0", DeployFailed: true}}
+	message, err = notificationMessage(v1alpha2.NotificationSettings{}, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("The following dependency builds have failed: com.test:test:1.0. The following dependencies failed to deploy: com.test:a:1.0, com.test:b:1.0."))
+
+	cb.Status.ArtifactState = cb.Status.ArtifactState[1:2]
+	message, err = notificationMessage(v1alpha2.NotificationSettings{}, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("The following dependencies failed to deploy: com.test:a:1.0."))
+}
+
+func TestConfiguredNotificationTemplates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	notification := v1alpha2.NotificationSettings{
+		SuccessTemplate: "{{ .ComponentBuild.Spec.Tag }} is ready, {{ .Deployed }}/{{ .Total }} deployed",
+		FailureTemplate: "{{ range .Artifacts }}{{ if .DeployFailed }}{{ .GAV }}: {{ .LastDeployFailure }} after {{ .DeployAttempts }} attempts{{ end }}{{ end }}",
+	}
+	cb := defaultComponentBuild()
+	cb.Status.State = v1alpha2.ComponentBuildStateComplete
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, Built: true, Deployed: true}}
+	message, err := notificationMessage(notification, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("1.0 is ready, 1/1 deployed"))
+
+	cb.Status.State = v1alpha2.ComponentBuildStateFailed
+	cb.Status.ArtifactState = []v1alpha2.ArtifactState{{GAV: artifact, Built: true, DeployFailed: true, DeployAttempts: 5, LastDeployFailure: "401 Unauthorized"}}
+	message, err = notificationMessage(notification, &cb)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("com.test:test:1.0: 401 Unauthorized after 5 attempts"))
+}
+
+func TestInvalidNotificationTemplates(t *testing.T) {
+	g := NewGomegaWithT(t)
+	cb := defaultComponentBuild()
+	cb.Status.State = v1alpha2.ComponentBuildStateComplete
+	//a template that does not parse is reported even when another template is used
+	message, err := notificationMessage(v1alpha2.NotificationSettings{FailureTemplate: "{{ .Failed "}, &cb)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("invalid " + FailureTemplate))
+	g.Expect(message).To(Equal("/retest Success all dependency builds have completed."))
+
+	//a template that fails to render falls back to the default
+	message, err = notificationMessage(v1alpha2.NotificationSettings{SuccessTemplate: "{{ .Missing }}"}, &cb)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("invalid " + SuccessTemplate))
+	g.Expect(message).To(Equal("/retest Success all dependency builds have completed."))
+}
+
+func TestInvalidTemplateReportedInStatus(t *testing.T) {
+	g := NewGomegaWithT(t)
+	client, reconciler := setupClientAndReconciler()
+	ctx := context.TODO()
+	setNamespaceSettings(g, client, func(spec *v1alpha2.DeploymentTargetSpec) {
+		spec.Notification = &v1alpha2.NotificationSettings{ProgressTemplate: "{{ if }}"}
+	})
+	cb := failedComponentBuild("https://example.com/test/test/pr/1")
+	g.Expect(client.Create(ctx, cb)).NotTo(HaveOccurred())
+	g.Expect(reconciler.notifyResult(ctx, ctrl.Log, cb, cb.DeepCopy())).To(Succeed())
+	condition := meta.FindStatusCondition(cb.Status.Conditions, v1alpha2.ConditionTemplatesValid)
+	g.Expect(condition).NotTo(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(v1alpha2.ReasonInvalidTemplate))
+	g.Expect(condition.Message).To(ContainSubstring(ProgressTemplate))
+}
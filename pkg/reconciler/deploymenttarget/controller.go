@@ -0,0 +1,15 @@
+package deploymenttarget
+
+import (
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/apheleia-project/apheleia/pkg/config"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+)
+
+func SetupNewReconcilerWithManager(mgr ctrl.Manager, store *config.Store) error {
+	r := newReconciler(mgr, store)
+	return ctrl.NewControllerManagedBy(mgr).For(&v1alpha2.DeploymentTarget{}).
+		WithOptions(controller.Options{MaxConcurrentReconciles: store.Get().MaxConcurrentReconciles}).
+		Complete(r)
+}
@@ -0,0 +1,126 @@
+package deploymenttarget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	"github.com/apheleia-project/apheleia/pkg/config"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// secretRecheckInterval is how often a target with missing secrets is checked again, secrets are not watched
+	// as that would mean caching every secret in the cluster
+	secretRecheckInterval = time.Minute
+)
+
+// ReconcileDeploymentTarget reports whether a DeploymentTarget is usable
+type ReconcileDeploymentTarget struct {
+	client client.Client
+	// secretReader reads secrets directly from the API server rather than the cache
+	secretReader client.Reader
+	// config holds the settings of the controller, which can change between reconciles
+	config *config.Store
+}
+
+func newReconciler(mgr ctrl.Manager, store *config.Store) reconcile.Reconciler {
+	return &ReconcileDeploymentTarget{
+		client:       mgr.GetClient(),
+		secretReader: mgr.GetAPIReader(),
+		config:       store,
+	}
+}
+
+func (r *ReconcileDeploymentTarget) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	var cancel context.CancelFunc
+	ctx, cancel = context.WithTimeout(ctx, r.config.Get().ReconcileTimeout.Duration)
+	defer cancel()
+	log := ctrl.Log.WithName("deploymenttarget").WithValues("request", request.NamespacedName)
+
+	target := v1alpha2.DeploymentTarget{}
+	err := r.client.Get(ctx, request.NamespacedName, &target)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	result := reconcile.Result{}
+	status, reason, message := metav1.ConditionTrue, v1alpha2.ReasonTargetValid, fmt.Sprintf("Artifacts will be deployed to %s", target.Spec.Location())
+	if errs := target.Spec.Validate(field.NewPath("spec")); len(errs) > 0 {
+		status, reason, message = metav1.ConditionFalse, v1alpha2.ReasonInvalidSpec, errs.ToAggregate().Error()
+	} else {
+		missing, err := r.missingSecrets(ctx, &target)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		if len(missing) > 0 {
+			status, reason, message = metav1.ConditionFalse, v1alpha2.ReasonSecretMissing, strings.Join(missing, ", ")
+			result.RequeueAfter = secretRecheckInterval
+		}
+	}
+	log.Info("Checked DeploymentTarget", "usable", status, "reason", reason)
+
+	target.Status.ObservedGeneration = target.Generation
+	meta.SetStatusCondition(&target.Status.Conditions, metav1.Condition{
+		Type:               v1alpha2.ConditionUsable,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: target.Generation,
+	})
+	return result, r.client.Status().Update(ctx, &target)
+}
+
+type requiredSecret struct {
+	name string
+	keys []string
+}
+
+// missingSecrets returns a description of each referenced secret or secret key that does not exist
+func (r *ReconcileDeploymentTarget) missingSecrets(ctx context.Context, target *v1alpha2.DeploymentTarget) ([]string, error) {
+	required := []requiredSecret{{name: target.Spec.GetImageSecret(), keys: []string{v1.DockerConfigJsonKey}}}
+	credentials := target.Spec.GetCredentialsSecret()
+	switch target.Spec.GetRepositoryKind() {
+	case v1alpha2.RepositoryKindCodeArtifact:
+		required = append(required, requiredSecret{name: credentials, keys: []string{v1alpha2.CodeArtifactAccessKey, v1alpha2.CodeArtifactSecretKey}})
+	case v1alpha2.RepositoryKindMaven:
+		if credentials != "" {
+			required = append(required, requiredSecret{name: credentials, keys: []string{v1alpha2.MavenUsernameKey, v1alpha2.MavenPasswordKey}})
+		}
+	case v1alpha2.RepositoryKindOCI:
+		if credentials != target.Spec.GetImageSecret() {
+			required = append(required, requiredSecret{name: credentials, keys: []string{v1.DockerConfigJsonKey}})
+		}
+	}
+	var missing []string
+	for _, i := range required {
+		secret := v1.Secret{}
+		err := r.secretReader.Get(ctx, types.NamespacedName{Namespace: target.Namespace, Name: i.name}, &secret)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				missing = append(missing, fmt.Sprintf("secret %s does not exist", i.name))
+				continue
+			}
+			return nil, err
+		}
+		for _, key := range i.keys {
+			if len(secret.Data[key]) == 0 {
+				missing = append(missing, fmt.Sprintf("secret %s has no %s key", i.name, key))
+			}
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,138 @@
+package deploymenttarget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/apheleia-project/apheleia/pkg/apis/apheleia/v1alpha2"
+	aph "github.com/apheleia-project/apheleia/pkg/client/clientset/versioned/scheme"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	namespace = "default"
+	name      = "test"
+)
+
+func setupClientAndReconciler(objs ...runtimeclient.Object) (runtimeclient.Client, *ReconcileDeploymentTarget) {
+	scheme := runtime.NewScheme()
+	_ = aph.AddToScheme(scheme)
+	_ = v1.AddToScheme(scheme)
+	client := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	reconciler := &ReconcileDeploymentTarget{client: client, secretReader: client}
+	return client, reconciler
+}
+
+func defaultDeploymentTarget() *v1alpha2.DeploymentTarget {
+	return &v1alpha2.DeploymentTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec: v1alpha2.DeploymentTargetSpec{
+			RepositoryURL: "https://test.test/maven/",
+			CodeArtifact:  &v1alpha2.CodeArtifactSettings{Domain: "test", Owner: "test"},
+		},
+	}
+}
+
+func secret(name string, keys ...string) *v1.Secret {
+	s := v1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Data: map[string][]byte{}}
+	for _, k := range keys {
+		s.Data[k] = []byte("value")
+	}
+	return &s
+}
+
+func TestDeploymentTargetUsable(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(dt *v1alpha2.DeploymentTarget)
+		objs    []runtimeclient.Object
+		status  metav1.ConditionStatus
+		reason  string
+		message string
+		requeue time.Duration
+	}{
+		{
+			name:   "usable",
+			objs:   []runtimeclient.Object{secret(v1alpha2.DefaultCredentialsSecret, "access-key", "secret-key"), secret(v1alpha2.DefaultImageSecret, v1.DockerConfigJsonKey)},
+			status: metav1.ConditionTrue,
+			reason: v1alpha2.ReasonTargetValid,
+		},
+		{
+			name:    "missing secrets",
+			status:  metav1.ConditionFalse,
+			reason:  v1alpha2.ReasonSecretMissing,
+			message: "secret jvm-build-image-secrets does not exist, secret aws-secrets does not exist",
+			requeue: secretRecheckInterval,
+		},
+		{
+			name:    "custom secret missing key",
+			modify:  func(dt *v1alpha2.DeploymentTarget) { dt.Spec.CredentialsSecret = "custom" },
+			objs:    []runtimeclient.Object{secret("custom", "access-key"), secret(v1alpha2.DefaultImageSecret, v1.DockerConfigJsonKey)},
+			status:  metav1.ConditionFalse,
+			reason:  v1alpha2.ReasonSecretMissing,
+			message: "secret custom has no secret-key key",
+			requeue: secretRecheckInterval,
+		},
+		{
+			name: "maven without credentials",
+			modify: func(dt *v1alpha2.DeploymentTarget) {
+				dt.Spec.RepositoryKind = v1alpha2.RepositoryKindMaven
+				dt.Spec.CodeArtifact = nil
+			},
+			objs:   []runtimeclient.Object{secret(v1alpha2.DefaultImageSecret, v1.DockerConfigJsonKey)},
+			status: metav1.ConditionTrue,
+			reason: v1alpha2.ReasonTargetValid,
+		},
+		{
+			name: "maven credentials missing password",
+			modify: func(dt *v1alpha2.DeploymentTarget) {
+				dt.Spec.RepositoryKind = v1alpha2.RepositoryKindMaven
+				dt.Spec.CredentialsSecret = "nexus"
+				dt.Spec.CodeArtifact = nil
+			},
+			objs:    []runtimeclient.Object{secret("nexus", "username"), secret(v1alpha2.DefaultImageSecret, v1.DockerConfigJsonKey)},
+			status:  metav1.ConditionFalse,
+			reason:  v1alpha2.ReasonSecretMissing,
+			message: "secret nexus has no password key",
+			requeue: secretRecheckInterval,
+		},
+		{
+			name:    "invalid spec",
+			modify:  func(dt *v1alpha2.DeploymentTarget) { dt.Spec.CodeArtifact = nil },
+			status:  metav1.ConditionFalse,
+			reason:  v1alpha2.ReasonInvalidSpec,
+			message: "spec.codeArtifact",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGomegaWithT(t)
+			dt := defaultDeploymentTarget()
+			if tt.modify != nil {
+				tt.modify(dt)
+			}
+			client, reconciler := setupClientAndReconciler(append(tt.objs, dt)...)
+			ctx := context.TODO()
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Namespace: namespace, Name: name}})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result.RequeueAfter).To(Equal(tt.requeue))
+
+			g.Expect(client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, dt)).NotTo(HaveOccurred())
+			usable := meta.FindStatusCondition(dt.Status.Conditions, v1alpha2.ConditionUsable)
+			g.Expect(usable).NotTo(BeNil())
+			g.Expect(usable.Status).To(Equal(tt.status))
+			g.Expect(usable.Reason).To(Equal(tt.reason))
+			g.Expect(usable.Message).To(ContainSubstring(tt.message))
+			g.Expect(usable.ObservedGeneration).To(Equal(dt.Generation))
+		})
+	}
+}